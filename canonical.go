@@ -0,0 +1,159 @@
+package tuples
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// numberRe matches decimal numbers normalized in the canonical form. The
+// exponent is limited to keep normalized numbers short.
+var numberRe = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d{1,3})?$`)
+
+// Canonicalize rewrites the tuples string data into the canonical form. Two
+// strings that read the same tuples have the same canonical form, regardless
+// of their formatting:
+//   - tuples are delimited by a single space
+//   - fields are written with the default delimiters, i.e. "h=700,w=350",
+//     fields of tuples and nested tuples are sorted by key, values of
+//     a repeated key keep their order
+//   - values are quoted only when it's needed, bare keys are written with an
+//     empty value, i.e. `ro=""`
+//   - decimal numbers are written in the shortest form, i.e. "700" for
+//     "0700.0" and "1000" for "1e3", with the WithCanonicalNumbers option,
+//     by default "1.10" and "1.1" are different values
+//
+// Options are used to read data. Positional and header tuples are written
+// with their keys, i.e. positions and header keys. List items keep their
+// order.
+func Canonicalize(data []byte, opts ...Option) ([]byte, error) {
+	o := newOptions(opts)
+
+	s, err := newScanner(bytes.NewReader(data), o.scannerOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+
+	for n := 0; s.next(); n++ {
+		tuple, err := s.tuple()
+		if err != nil {
+			return nil, err
+		}
+
+		if tuple, err = dedupe(tuple, o.duplicateKeys, s.pos); err != nil {
+			return nil, err
+		}
+
+		if n > 0 {
+			b.WriteRune(tuplesDelimiter)
+		}
+
+		writeCanonicalFields(&b, tuple, o.canonicalValue)
+	}
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return []byte(b.String()), nil
+}
+
+// Fingerprint returns the SHA-256 checksum of the canonical form of the tuples
+// string data, see Canonicalize. Semantically identical tuples strings have
+// the same fingerprint, so it can be used as a cache key.
+func Fingerprint(data []byte, opts ...Option) ([32]byte, error) {
+	c, err := Canonicalize(data, opts...)
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	return sha256.Sum256(c), nil
+}
+
+// writeCanonicalFields writes the fields sorted by key, fields of nested
+// tuples too. Plain values are written by the value function, i.e.
+// canonicalNumber, and quoted when needed. Empty positional fields are
+// skipped.
+func writeCanonicalFields(b *strings.Builder, flds []node, value func(string) string) {
+	sorted := slices.Clone(flds)
+	slices.SortStableFunc(sorted, func(a, b node) int { return strings.Compare(a.key, b.key) })
+
+	n := 0
+	for _, fld := range sorted {
+		if isEmpty(fld) {
+			continue
+		}
+
+		if n > 0 {
+			b.WriteRune(fieldsDelimiter)
+		}
+
+		b.WriteString(fld.key)
+		b.WriteRune(keyValDelimiter)
+		writeCanonicalValue(b, fld, value)
+		n++
+	}
+}
+
+func writeCanonicalValue(b *strings.Builder, n node, value func(string) string) {
+	switch n.kind {
+	case nodeTuple:
+		b.WriteRune(tupleOpen)
+		writeCanonicalFields(b, n.nodes, value)
+		b.WriteRune(tupleClose)
+	case nodeList:
+		b.WriteRune(listOpen)
+
+		for i, item := range n.nodes {
+			if i > 0 {
+				b.WriteRune(fieldsDelimiter)
+			}
+
+			writeCanonicalValue(b, item, value)
+		}
+
+		b.WriteRune(listClose)
+	default:
+		b.WriteString(defaultOptions.quoteValue(value(fieldValue(n))))
+	}
+}
+
+// canonicalValue returns the canonical form of the plain value s, the
+// shortest number form with the WithCanonicalNumbers option, s otherwise.
+func (o *options) canonicalValue(s string) string {
+	if o.numbers {
+		return canonicalNumber(s)
+	}
+
+	return s
+}
+
+// canonicalNumber returns the shortest form of the decimal number s. Other
+// values are returned as is.
+func canonicalNumber(s string) string {
+	if !numberRe.MatchString(s) {
+		return s
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return s
+	}
+
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	// A decimal number has a finite representation, find the shortest one.
+	for prec := 1; ; prec++ {
+		f := r.FloatString(prec)
+		if v, ok := new(big.Rat).SetString(f); ok && v.Cmp(r) == 0 {
+			return f
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package tuples_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/antklim/tuples"
+)
+
+func TestCanonicalize(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		opts []tuples.Option
+		out  string
+	}{
+		{desc: "empty", in: " \n ", out: ""},
+		{desc: "sorted keys", in: "w=350,h=700,f=png", out: "f=png,h=700,w=350"},
+		{desc: "whitespace", in: "\th=700\n\n  h=900  ", out: "h=700 h=900"},
+		{desc: "repeated keys", in: "t=b,h=1,t=a", out: "h=1,t=b,t=a"},
+		{desc: "quoting", in: `f="png",note="a b",q="""x""",e=""`, out: `e="",f=png,note="a b",q="""x"""`},
+		{desc: "brackets in top level value", in: "note=a(b)", out: `note="a(b)"`},
+		{desc: "quoted nested tuple text", in: `size="(w=1)"`, out: `size="(w=1)"`},
+		{
+			desc: "numbers",
+			in:   "a=0700,b=+1.50,c=1e3,d=-0,e=.5,f=2.5E-2,g=1.,h=0x10,i=1_000,j=12345678901234567890.0",
+			opts: []tuples.Option{tuples.WithCanonicalNumbers()},
+			out:  "a=700,b=1.5,c=1000,d=0,e=0.5,f=0.025,g=1,h=0x10,i=1_000,j=12345678901234567890",
+		},
+		{desc: "numbers kept", in: "v=1.10,mode=0755,n=1e3", out: "mode=0755,n=1e3,v=1.10"},
+		{desc: "quoted numbers", in: `h="0700"`, opts: []tuples.Option{tuples.WithCanonicalNumbers()}, out: "h=700"},
+		{
+			desc: "nested tuples and lists",
+			in:   "s=(w=01,h=2,x=[b,(z=1,y=2)])",
+			opts: []tuples.Option{tuples.WithCanonicalNumbers()},
+			out:  "s=(h=2,w=1,x=[b,(y=2,z=1)])",
+		},
+		{desc: "bare keys", in: "uid=0,ro,s=(x,a=1)", opts: []tuples.Option{tuples.WithBareKeys()}, out: `ro="",s=(a=1,x=""),uid=0`},
+		{
+			desc: "custom delimiters",
+			in:   "w:350;h:700;n:\"a,b\"",
+			opts: []tuples.Option{tuples.WithFieldsDelimiter(';'), tuples.WithKeyValueDelimiter(':')},
+			out:  `h=700,n="a,b",w=350`,
+		},
+		{desc: "positional", in: "700,,jpeg", opts: []tuples.Option{tuples.WithPositional()}, out: "0=700,2=jpeg"},
+		{desc: "header", in: "@w,h 350,700 ,900", opts: []tuples.Option{tuples.WithHeader()}, out: "h=700,w=350 h=900"},
+		{
+			desc: "duplicate keys policy",
+			in:   "h=1,h=2",
+			opts: []tuples.Option{tuples.WithDuplicateKeys(tuples.DuplicateKeysFirstWins)},
+			out:  "h=1",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			out, err := tuples.Canonicalize([]byte(tC.in), tC.opts...)
+			if err != nil {
+				t.Fatalf("unexpected Canonicalize() error: %v", err)
+			}
+
+			if string(out) != tC.out {
+				t.Errorf("Canonicalize() output:\ngot  %s\nwant %s", out, tC.out)
+			}
+
+			again, err := tuples.Canonicalize(out)
+			if err != nil {
+				t.Fatalf("unexpected Canonicalize() error of the canonical form: %v", err)
+			}
+
+			if string(again) != tC.out {
+				t.Errorf("Canonicalize() of the canonical form:\ngot  %s\nwant %s", again, tC.out)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeFails(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		opts []tuples.Option
+		err  error
+	}{
+		{
+			desc: "invalid tuple",
+			in:   "h=1 w=(",
+			err:  errors.New("tuples: scan failed: tuple #2 invalid field #1"),
+		},
+		{
+			desc: "duplicate key",
+			in:   "h=1,h=2",
+			opts: []tuples.Option{tuples.WithDuplicateKeys(tuples.DuplicateKeysError)},
+			err:  errors.New(`tuples: tuple #1 duplicate key "h"`),
+		},
+		{
+			desc: "invalid options",
+			in:   "h=1",
+			opts: []tuples.Option{tuples.WithKeyValueDelimiter(',')},
+			err:  errors.New("tuples: invalid delimiters: fields and key-value delimiters are equal"),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			_, err := tuples.Canonicalize([]byte(tC.in), tC.opts...)
+			if !eqErrors(err, tC.err) {
+				t.Errorf("Canonicalize() error mismatch:\ngot  %v\nwant %v", err, tC.err)
+			}
+
+			if _, err := tuples.Fingerprint([]byte(tC.in), tC.opts...); !eqErrors(err, tC.err) {
+				t.Errorf("Fingerprint() error mismatch:\ngot  %v\nwant %v", err, tC.err)
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := func(s string, opts ...tuples.Option) [32]byte {
+		t.Helper()
+
+		f, err := tuples.Fingerprint([]byte(s), opts...)
+		if err != nil {
+			t.Fatalf("unexpected Fingerprint() error: %v", err)
+		}
+
+		return f
+	}
+
+	base := fingerprint("h=700,w=350,f=png")
+
+	same := []struct {
+		in   string
+		opts []tuples.Option
+	}{
+		{in: "h=700,w=350,f=png"},
+		{in: "  f=\"png\",w=350.0,h=0700\n", opts: []tuples.Option{tuples.WithCanonicalNumbers()}},
+		{in: "f:png;h:700;w:350", opts: []tuples.Option{tuples.WithFieldsDelimiter(';'), tuples.WithKeyValueDelimiter(':')}},
+		{in: "@h,w,f 700,350,png", opts: []tuples.Option{tuples.WithHeader()}},
+	}
+	for _, s := range same {
+		if f := fingerprint(s.in, s.opts...); f != base {
+			t.Errorf("Fingerprint(%q) = %x, want %x", s.in, f, base)
+		}
+	}
+
+	for _, in := range []string{"h=700,w=350,f=jpeg", "h=700,w=350", "h=700,w=350,f=png h=1", "h=0700,w=350,f=png"} {
+		if f := fingerprint(in); f == base {
+			t.Errorf("Fingerprint(%q) equals the fingerprint of a different string", in)
+		}
+	}
+}
+
+func TestFingerprintNumbers(t *testing.T) {
+	testCases := []struct {
+		a, b string
+	}{
+		{a: "v=1.10", b: "v=1.1"},
+		{a: "mode=0755", b: "mode=755"},
+		{a: "n=1e3", b: "n=1000"},
+		{a: "s=(v=1.10)", b: "s=(v=1.1)"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.a, func(t *testing.T) {
+			a, err := tuples.Fingerprint([]byte(tC.a))
+			if err != nil {
+				t.Fatalf("unexpected Fingerprint() error: %v", err)
+			}
+
+			b, err := tuples.Fingerprint([]byte(tC.b))
+			if err != nil {
+				t.Fatalf("unexpected Fingerprint() error: %v", err)
+			}
+
+			if a == b {
+				t.Errorf("Fingerprint(%q) equals Fingerprint(%q)", tC.a, tC.b)
+			}
+
+			a, _ = tuples.Fingerprint([]byte(tC.a), tuples.WithCanonicalNumbers())
+			b, _ = tuples.Fingerprint([]byte(tC.b), tuples.WithCanonicalNumbers())
+
+			if a != b {
+				t.Errorf("Fingerprint(%q) differs from Fingerprint(%q) with WithCanonicalNumbers", tC.a, tC.b)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package tuples
+
+import (
+	"errors"
+	"reflect"
+)
+
+var (
+	errMissingNotMarkable = errors.New("missing values can be marked only in slices of pointers, interfaces or Optional")
+	errCollectColumns     = errors.New("repeated keys cannot be collected into columns")
+)
+
+// Missing describes how a column gets a value for a tuple that lacks the
+// column key on columnar decoding.
+type Missing int
+
+const (
+	// MissingZero appends the zero value of the column element type. Pointers
+	// are allocated and Optional values are set. It's the default.
+	MissingZero Missing = iota
+	// MissingMark appends a nil pointer, a nil interface or an unset Optional.
+	// Columns must be slices of such types.
+	MissingMark
+)
+
+// isColumns reports whether every tagged field of the struct type t is
+// a slice, i.e. t can hold tuples as columns.
+func isColumns(t reflect.Type) bool {
+	sf := cachedTypeFields(t)
+	if len(sf.fields) == 0 {
+		return false
+	}
+
+	for _, f := range sf.fields {
+		if ft, _ := t.FieldByName(f.name); ft.Type.Kind() != reflect.Slice {
+			return false
+		}
+	}
+
+	return true
+}
+
+// columns decodes tuples into the struct v of slices, one slice per key. Every
+// tuple appends one element to every slice. Repeated keys of a tuple are
+// handled by the duplicate keys policy, DuplicateKeysCollect causes
+// UnmarshalError.
+func (d *decoder) columns(v reflect.Value) error {
+	if !isColumns(v.Type()) {
+		return &UnmarshalError{Value: "array", Type: v.Type()}
+	}
+
+	sf := cachedTypeFields(v.Type())
+
+	cols := make([]reflect.Value, len(sf.fields))
+	for i, f := range sf.fields {
+		col := v.FieldByName(f.name)
+		if d.opts.missing == MissingMark && !markable(col.Type().Elem()) {
+			return &UnmarshalError{Err: errMissingNotMarkable, Value: "missing", Type: col.Type()}
+		}
+
+		col.Set(reflect.MakeSlice(col.Type(), 0, 0))
+		cols[i] = col
+	}
+
+	for d.s.next() {
+		flds, err := d.s.tuple()
+		if err != nil {
+			return err
+		}
+
+		if flds, err = dedupe(flds, d.opts.duplicateKeys, d.s.pos); err != nil {
+			return err
+		}
+
+		byName, err := d.columnFields(sf, flds, v.Type())
+		if err != nil {
+			return err
+		}
+
+		for i, f := range sf.fields {
+			col := cols[i]
+			elem := reflect.New(col.Type().Elem()).Elem()
+
+			if fld, ok := byName[f.name]; ok {
+				if err := d.setField(elem, fld); err != nil {
+					return err
+				}
+			} else if d.opts.missing == MissingZero {
+				fillZero(elem)
+			}
+
+			col.Set(reflect.Append(col, elem))
+		}
+	}
+
+	return d.s.err
+}
+
+// columnFields returns the tuple fields by the names of the column fields of
+// the struct type t. Every tuple is one element of a column, so the last value
+// of a repeated key wins. Collecting repeated keys is ambiguous and causes
+// UnmarshalError.
+func (d *decoder) columnFields(sf typFields, flds []node, t reflect.Type) (map[string]node, error) {
+	byName := make(map[string]node, len(flds))
+
+	for _, fld := range flds {
+		if isEmpty(fld) {
+			continue
+		}
+
+		f, ok := sf.lookup(fld)
+		if !ok {
+			continue
+		}
+
+		if _, ok := byName[f.name]; ok && d.opts.duplicateKeys == DuplicateKeysCollect {
+			return nil, &UnmarshalError{Err: errCollectColumns, Value: fld.key, Type: t}
+		}
+
+		byName[f.name] = fld
+	}
+
+	return byName, nil
+}
+
+// markable reports whether the zero value of t can mark a missing value.
+func markable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return true
+	}
+
+	return reflect.PointerTo(t).Implements(optionalSetterType)
+}
+
+// fillZero allocates the pointers and sets the Optional values of v to the
+// zero value of the underlying type.
+func fillZero(v reflect.Value) {
+	if v.CanAddr() && v.Addr().Type().Implements(optionalSetterType) {
+		o := v.Addr().Interface().(optionalSetter)
+
+		ov := reflect.New(o.optionalType()).Elem()
+		fillZero(ov)
+		o.setOptional(ov)
+
+		return
+	}
+
+	if v.Kind() == reflect.Pointer {
+		v.Set(reflect.New(v.Type().Elem()))
+		fillZero(v.Elem())
+	}
+}
+
+// columns writes the struct v of slices as tuples, one tuple per slice index.
+// Omitted elements are not written, a tuple without elements is skipped.
+func (e *encoder) columns(v reflect.Value) error {
+	sf := cachedTypeFields(v.Type())
+
+	cols := make([]reflect.Value, len(sf.fields))
+	seps := make([]rune, len(sf.fields))
+	rows := 0
+
+	for i, f := range sf.fields {
+		sep, err := e.opts.fieldListDelimiter(f)
+		if err != nil {
+			return err
+		}
+
+		cols[i], seps[i] = v.FieldByName(f.name), sep
+		if n := cols[i].Len(); n > rows {
+			rows = n
+		}
+	}
+
+	n := 0
+	for r := 0; r < rows; r++ {
+		if !e.hasRow(cols, r) {
+			continue
+		}
+
+		if n > 0 {
+			if _, err := e.b.WriteRune(tuplesDelimiter); err != nil {
+				return &MarshalError{err}
+			}
+		}
+
+		i := 0
+		for c, col := range cols {
+			if r >= col.Len() {
+				continue
+			}
+
+			w, err := e.writeField(sf.fields[c].tag, col.Index(r), i, seps[c])
+			if err != nil {
+				return err
+			}
+
+			i += w
+		}
+
+		n++
+	}
+
+	return nil
+}
+
+// hasRow reports whether any column has an element to write at the index r.
+func (e *encoder) hasRow(cols []reflect.Value, r int) bool {
+	for _, col := range cols {
+		if r < col.Len() && !omitted(col.Index(r)) && !e.omittedBare(col.Index(r)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,19 +2,59 @@ package tuples
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
 	"strconv"
+	"strings"
+)
+
+var (
+	errNoKeyField = errors.New("no comparable key field")
+	errMissingKey = errors.New("tuple has no key field")
 )
 
 // Unmarshal parses the tuples-encoded data and stores the result in the value
 // pointed to by v.
 // If v is nil or not a pointer, Unmarshal returns an InvalidUnmarshalError.
-func Unmarshal(data []byte, v any) error {
+//
+// Pointer fields are allocated and Optional fields are set only when the tuple
+// contains the field key, so an absent field can be told apart from a zero one.
+//
+// Tuples can be decoded into a slice of interfaces, i.e. []Op. The concrete
+// type of every element is looked up by the tuple kind, i.e. "kind=resize",
+// see RegisterKind. Tuples without a known kind decoded into
+// map[string]any when the interface is empty.
+//
+// Tuples can be decoded into a map of structs, i.e. map[string]T. The map key
+// is the value of the struct field marked with the key tag option, i.e.
+// `tuples:"id,key"`. Tuples with the same key value cause DuplicateKeyError.
+//
+// Tuples can be decoded into a struct of slices as columns, one slice per key,
+// with the WithColumns option, i.e. "h=700,w=350 h=900,w=450" decoded into
+// struct{H []int; W []int}. Every tagged field of the struct must be a slice.
+// Use WithMissing to define values for tuples that lack a key.
+//
+// By default tuples are decoded into a slice or an array from the start. Use
+// WithDecodeMode to append tuples to the existing elements or to merge them.
+//
+// Positional tuples, i.e. "700,350,jpeg", are decoded with the WithPositional
+// option. Values are mapped to struct fields by the pos tag option, i.e.
+// `tuples:",pos=0"`, or by the order of tagged fields.
+//
+// Tuples that declare keys once by the header tuple, i.e. "@h,w,f 700,350,jpeg",
+// are decoded with the WithHeader option as if every tuple had the keys.
+//
+// Repeated keys of a tuple are decoded into a slice or array field element by
+// element, i.e. "tag=a,tag=b" decoded into []string{"a", "b"}. A value is split
+// into list elements by the list delimiter, see WithListDelimiter.
+//
+// Options, i.e. delimiters, are the same as the reader options.
+func Unmarshal(data []byte, v any, opts ...Option) error {
 	var d decoder
 
-	if err := d.init(data); err != nil {
+	if err := d.init(data, opts...); err != nil {
 		return err
 	}
 
@@ -70,10 +110,12 @@ func (e *UnmarshalError) Unwrap() error {
 type decoder struct {
 	data []byte
 	s    *scanner
+	opts options
 }
 
-func (d *decoder) init(data []byte) error {
+func (d *decoder) init(data []byte, opts ...Option) error {
 	d.data = data
+	d.opts = newOptions(opts)
 
 	err := d.initScanner(bytes.NewReader(data))
 
@@ -81,7 +123,7 @@ func (d *decoder) init(data []byte) error {
 }
 
 func (d *decoder) initScanner(r io.Reader) error {
-	s, err := newScanner(r)
+	s, err := newScanner(r, d.opts.scannerOptions()...)
 	if err != nil {
 		return err
 	}
@@ -129,32 +171,29 @@ func (d *decoder) array(v reflect.Value) error {
 		break
 	case reflect.Interface:
 		return d.arrayInterface(v)
+	case reflect.Map:
+		return d.keyedMap(v)
+	case reflect.Struct:
+		if d.opts.columns {
+			return d.columns(v)
+		}
+
+		return &UnmarshalError{Value: "array", Type: v.Type()}
 	default:
 		return &UnmarshalError{Value: "array", Type: v.Type()}
 	}
 
-	// TODO(feat): add decoder options that allow addition of the elements to non-empty
-	//			 slice or array. Currently elements are overwritten from the start.
-	// i := v.Len()
+	if d.opts.decodeMode == DecodeMerge {
+		return d.mergeList(v)
+	}
+
 	i := 0
-	for d.s.next() {
-		if v.Kind() == reflect.Slice {
-			// Grow slice if necessary.
-			if i >= v.Cap() {
-				newcap := v.Cap() + v.Cap()/2 //nolint: gomnd
-				if newcap < 4 {               //nolint: gomnd
-					newcap = 4
-				}
-
-				newv := reflect.MakeSlice(v.Type(), v.Len(), newcap)
-				reflect.Copy(newv, v)
-				v.Set(newv)
-			}
+	if d.opts.decodeMode == DecodeAppend {
+		i = listLen(v)
+	}
 
-			if i >= v.Len() {
-				v.SetLen(i + 1)
-			}
-		}
+	for d.s.next() {
+		growSlice(v, i)
 
 		if i < v.Len() {
 			// Decode into element.
@@ -166,25 +205,306 @@ func (d *decoder) array(v reflect.Value) error {
 		i++
 	}
 
-	if i < v.Len() {
-		if v.Kind() == reflect.Array {
-			// Add zeros to the rest of the array.
-			z := reflect.Zero(v.Type().Elem())
+	if d.s.err != nil {
+		return d.s.err
+	}
+
+	d.trimList(v, i)
+
+	return nil
+}
+
+// mergeList decodes tuples into the list v with the DecodeMerge mode. Tuples
+// are merged into the existing elements with the same key field value, other
+// tuples are appended.
+func (d *decoder) mergeList(v reflect.Value) error {
+	i := listLen(v)
+
+	keys, err := d.listKeys(v, i)
+	if err != nil {
+		return err
+	}
+
+	for d.s.next() {
+		merged, err := d.mergeElement(v, i, keys)
+		if err != nil {
+			return err
+		}
+
+		if !merged {
+			i++
+		}
+	}
 
-			for ; i < v.Len(); i++ {
-				v.Index(i).Set(z)
+	if d.s.err != nil {
+		return d.s.err
+	}
+
+	d.trimList(v, i)
+
+	return nil
+}
+
+// trimList cuts off the elements of the list v past the n decoded ones. The
+// rest of an array is set to zeros on overwriting. An empty slice is set
+// instead of a nil one.
+func (d *decoder) trimList(v reflect.Value, n int) {
+	if v.Kind() == reflect.Array {
+		if d.opts.decodeMode == DecodeOverwrite {
+			z := reflect.Zero(v.Type().Elem())
+			for ; n < v.Len(); n++ {
+				v.Index(n).Set(z)
 			}
-		} else {
-			// Cut off the rest of prefilled slice.
-			v.SetLen(i)
 		}
+
+		return
 	}
 
-	if i == 0 && v.Kind() == reflect.Slice {
+	if n < v.Len() {
+		v.SetLen(n)
+	}
+
+	if v.IsNil() {
 		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
 	}
+}
 
-	return nil
+// keyedMap decodes tuples into the map v of structs. Every struct stored by
+// its key field value. Tuples with the same key value are not allowed, unless
+// merging. In merge mode such tuples update the stored struct.
+func (d *decoder) keyedMap(v reflect.Value) error {
+	et := v.Type().Elem()
+
+	kf, err := keyField(et)
+	if err != nil {
+		return err
+	}
+
+	if v.IsNil() || d.opts.decodeMode == DecodeOverwrite {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+
+	for d.s.next() {
+		flds, err := d.s.tuple()
+		if err != nil {
+			return err
+		}
+
+		// Decode the tuple into a zero element to find out its key.
+		tmp := reflect.New(indirectType(et)).Elem()
+		if err := d.fields(tmp, flds); err != nil {
+			return err
+		}
+
+		key, ok, err := keyValue(tmp, kf)
+		if err != nil {
+			return err
+		}
+
+		if !ok || !hasField(flds, et, kf) {
+			return &UnmarshalError{Err: errMissingKey, Value: d.s.text(), Type: et}
+		}
+
+		mk, err := mapKey(key, v.Type().Key())
+		if err != nil {
+			return err
+		}
+
+		elem := reflect.New(et).Elem()
+
+		if existing := v.MapIndex(mk); existing.IsValid() {
+			if d.opts.decodeMode != DecodeMerge {
+				return &DuplicateKeyError{Key: fmt.Sprint(key), Tuple: d.s.pos}
+			}
+
+			elem.Set(existing)
+		}
+
+		if err := d.fields(indirect(elem), flds); err != nil {
+			return err
+		}
+
+		v.SetMapIndex(mk, elem)
+	}
+
+	return d.s.err
+}
+
+// mapKey converts the key field value to the map key type t. A string map key
+// is the text of the value, i.e. "65" for 65, other map keys must be of the
+// key field kind. It's the reverse of the encoder setKeyField.
+func mapKey(key any, t reflect.Type) (reflect.Value, error) {
+	kv := reflect.ValueOf(key)
+
+	switch {
+	case t.Kind() == reflect.String:
+		return reflect.ValueOf(fmt.Sprint(key)).Convert(t), nil
+	case kv.Kind() == t.Kind() && kv.Type().ConvertibleTo(t):
+		return kv.Convert(t), nil
+	default:
+		return reflect.Value{}, &UnmarshalError{Value: fmt.Sprint(key), Type: t}
+	}
+}
+
+// growSlice makes sure that the slice v has the element i.
+func growSlice(v reflect.Value, i int) {
+	if v.Kind() != reflect.Slice {
+		return
+	}
+
+	if i >= v.Cap() {
+		newcap := v.Cap() + v.Cap()/2 //nolint: gomnd
+		if newcap < 4 {               //nolint: gomnd
+			newcap = 4
+		}
+
+		newv := reflect.MakeSlice(v.Type(), v.Len(), newcap)
+		reflect.Copy(newv, v)
+		v.Set(newv)
+	}
+
+	if i >= v.Len() {
+		v.SetLen(i + 1)
+	}
+}
+
+// listLen returns the number of elements of the slice v, or the number of
+// elements of the array v before the first zero element.
+func listLen(v reflect.Value) int {
+	if v.Kind() == reflect.Slice {
+		return v.Len()
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		if v.Index(i).IsZero() {
+			return i
+		}
+	}
+
+	return v.Len()
+}
+
+// listKeys returns the positions of the first n elements of the list v by
+// their key field values.
+func (d *decoder) listKeys(v reflect.Value, n int) (map[any]int, error) {
+	kf, err := keyField(v.Type().Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[any]int, n)
+
+	for i := 0; i < n; i++ {
+		elem := unwrapElement(v.Index(i))
+		if !elem.IsValid() {
+			continue
+		}
+
+		key, ok, err := keyValue(elem, kf)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, dup := keys[key]; ok && !dup {
+			keys[key] = i
+		}
+	}
+
+	return keys, nil
+}
+
+// mergeElement decodes the scanned tuple into the element of the list v that
+// has the same key field value. If there is no such element the tuple is
+// decoded into the element i. It reports whether the tuple was merged into
+// an existing element.
+func (d *decoder) mergeElement(v reflect.Value, i int, keys map[any]int) (bool, error) {
+	flds, err := d.s.tuple()
+	if err != nil {
+		return false, err
+	}
+
+	kf, err := keyField(v.Type().Elem())
+	if err != nil {
+		return false, err
+	}
+
+	// Decode the tuple into a zero element to find out its key.
+	tmp := reflect.New(indirectType(v.Type().Elem())).Elem()
+	if err := d.fields(tmp, flds); err != nil {
+		return false, err
+	}
+
+	key, hasKey, err := keyValue(tmp, kf)
+	if err != nil {
+		return false, err
+	}
+
+	hasKey = hasKey && hasField(flds, v.Type().Elem(), kf)
+
+	if j, ok := keys[key]; hasKey && ok {
+		return true, d.fields(indirect(v.Index(j)), flds)
+	}
+
+	growSlice(v, i)
+
+	if i >= v.Len() {
+		return false, nil
+	}
+
+	if hasKey {
+		keys[key] = i
+	}
+
+	return false, d.fields(indirect(v.Index(i)), flds)
+}
+
+// keyField returns the key field of the struct type t or a pointer to it.
+func keyField(t reflect.Type) (field, error) {
+	st := indirectType(t)
+	if st.Kind() == reflect.Struct {
+		if kf, ok := cachedTypeFields(st).keyField(); ok {
+			return kf, nil
+		}
+	}
+
+	return field{}, &UnmarshalError{Err: errNoKeyField, Value: "key", Type: t}
+}
+
+// keyValue returns the key field value of the struct v. It reports whether
+// the key field is set.
+func keyValue(v reflect.Value, kf field) (any, bool, error) {
+	kv := unwrapElement(v.FieldByName(kf.name))
+	if !kv.IsValid() {
+		return nil, false, nil
+	}
+
+	if !kv.Type().Comparable() {
+		return nil, false, &UnmarshalError{Err: errNoKeyField, Value: "key", Type: kv.Type()}
+	}
+
+	return kv.Interface(), true, nil
+}
+
+// hasField reports whether the tuple has a field decoded into the struct
+// field f of the type t.
+func hasField(flds []node, t reflect.Type, f field) bool {
+	sf := cachedTypeFields(indirectType(t))
+	for _, fld := range flds {
+		if sfld, ok := sf.lookup(fld); ok && sfld.name == f.name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// indirectType walks down t until it gets to a non-pointer.
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
 }
 
 func (d *decoder) object(v reflect.Value) error {
@@ -193,20 +513,236 @@ func (d *decoder) object(v reflect.Value) error {
 		return err
 	}
 
+	return d.tuple(v, flds)
+}
+
+// tuple decodes the tuple fields into v, a struct, a map, a Tuple or an
+// interface.
+func (d *decoder) tuple(v reflect.Value, flds []node) error {
+	if v.Type() == tupleType {
+		return d.tupleFields(v, flds)
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		return d.polymorphic(v, flds)
+	case reflect.Map:
+		return d.mapFields(v, flds)
+	case reflect.Struct:
+		return d.fields(v, flds)
+	}
+
+	return &UnmarshalError{Value: "tuple", Type: v.Type()}
+}
+
+// mapFields decodes the tuple fields into the map v keyed by the field keys.
+// Repeated keys handled according to the duplicate keys policy.
+func (d *decoder) mapFields(v reflect.Value, flds []node) error {
+	mt := v.Type()
+	if mt.Key().Kind() != reflect.String {
+		return &UnmarshalError{Value: "tuple", Type: mt}
+	}
+
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(mt))
+	}
+
+	// A map of empty interfaces gets the same values as Unmarshal into any.
+	if mt.Elem().Kind() == reflect.Interface && mt.Elem().NumMethod() == 0 {
+		m, err := d.mapInterface(flds)
+		if err != nil {
+			return err
+		}
+
+		for key, val := range m {
+			v.SetMapIndex(reflect.ValueOf(key).Convert(mt.Key()), reflect.ValueOf(&val).Elem())
+		}
+
+		return nil
+	}
+
+	flds, err := dedupe(flds, d.opts.duplicateKeys, d.s.pos)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(flds))
+
 	for _, fld := range flds {
-		tag, val := fld[idxKey], fld[idxVal]
-		sf := cachedTypeFields(v.Type())
+		if isEmpty(fld) {
+			continue
+		}
+
+		key := reflect.ValueOf(fld.key).Convert(mt.Key())
 
-		if idx, ok := sf.fieldsByTag[tag]; ok {
-			if err := set(v.Field(idx), val); err != nil {
+		elem := reflect.New(mt.Elem()).Elem()
+		if err := d.setField(elem, fld); err != nil {
+			return err
+		}
+
+		if seen[fld.key] && d.opts.duplicateKeys == DuplicateKeysCollect {
+			if elem, err = collectMapValue(v.MapIndex(key), elem, fld); err != nil {
 				return err
 			}
 		}
+
+		seen[fld.key] = true
+		v.SetMapIndex(key, elem)
 	}
 
 	return nil
 }
 
+// collectMapValue returns the value elem of the repeated map key field fld
+// collected with the previous value prev of the key, see
+// DuplicateKeysCollect. Slices are appended, an interface gets the last
+// value and other types cannot collect values.
+func collectMapValue(prev, elem reflect.Value, fld node) (reflect.Value, error) {
+	switch elem.Kind() {
+	case reflect.Slice:
+		return reflect.AppendSlice(prev, elem), nil
+	case reflect.Interface:
+		return elem, nil
+	default:
+		return reflect.Value{}, &UnmarshalError{Err: errCollectNonSlice, Value: fieldValue(fld), Type: elem.Type()}
+	}
+}
+
+// polymorphic decodes the tuple fields into the interface v. The concrete
+// struct type is looked up by the tuple kind, see RegisterKind. A tuple
+// without a known kind decoded into a map when v is an empty interface.
+func (d *decoder) polymorphic(v reflect.Value, flds []node) error {
+	name, ok := tupleKind(flds, d.opts.kindKey)
+
+	var t reflect.Type
+	if ok {
+		t, ok = d.opts.kind(name)
+	}
+
+	if !ok {
+		if v.NumMethod() > 0 {
+			return &UnmarshalError{Err: errUnknownKind, Value: name, Type: v.Type()}
+		}
+
+		m, err := d.mapInterface(flds)
+		if err != nil {
+			return err
+		}
+
+		v.Set(reflect.ValueOf(m))
+
+		return nil
+	}
+
+	pv := reflect.New(t)
+	if err := d.fields(pv.Elem(), flds); err != nil {
+		return err
+	}
+
+	switch {
+	case t.Implements(v.Type()):
+		v.Set(pv.Elem())
+	case pv.Type().Implements(v.Type()):
+		v.Set(pv)
+	default:
+		return &UnmarshalError{Value: name, Type: v.Type()}
+	}
+
+	return nil
+}
+
+// fields decodes the tuple fields into the struct v.
+func (d *decoder) fields(v reflect.Value, flds []node) error {
+	sf := cachedTypeFields(v.Type())
+	seen := make(map[string]bool, len(flds))
+	collected := make(map[string]int)
+
+	for _, fld := range flds {
+		tag := fld.key
+
+		if isEmpty(fld) {
+			continue
+		}
+
+		if fld.positional && sf.posErr != nil {
+			return &UnmarshalError{Err: sf.posErr, Value: "tuple", Type: v.Type()}
+		}
+
+		var fv reflect.Value
+		f, ok := sf.lookup(fld)
+		if ok {
+			fv = v.FieldByName(f.name)
+		}
+
+		// Repeated keys of a list field are its elements, not duplicates.
+		if fv.IsValid() && isList(fv) {
+			if err := d.listField(fv, f, fld, collected); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if seen[tag] {
+			skip, err := d.repeatedKey(fv, fld)
+			if err != nil {
+				return err
+			}
+
+			if skip {
+				continue
+			}
+		}
+
+		seen[tag] = true
+
+		if fv.IsValid() {
+			if err := d.setField(fv, fld); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// listField appends the field fld value to the list struct field fv. The
+// number of elements of every list field stored so far is kept in collected.
+func (d *decoder) listField(fv reflect.Value, f field, fld node, collected map[string]int) error {
+	sep, err := d.opts.fieldListDelimiter(f)
+	if err != nil {
+		return err
+	}
+
+	n, ok := collected[fld.key]
+	if !ok {
+		// Values of the previous tuples are overwritten.
+		resetList(fv)
+	}
+
+	collected[fld.key], err = d.appendList(fv, n, fld, sep)
+
+	return err
+}
+
+// repeatedKey applies the duplicate keys policy to the repeated key field fld
+// of the struct field fv. It reports whether the field should be skipped.
+func (d *decoder) repeatedKey(fv reflect.Value, fld node) (bool, error) {
+	switch d.opts.duplicateKeys {
+	case DuplicateKeysError:
+		return false, &DuplicateKeyError{Key: fld.key, Tuple: d.s.pos}
+	case DuplicateKeysFirstWins:
+		return true, nil
+	case DuplicateKeysCollect:
+		if fv.IsValid() {
+			return false, &UnmarshalError{Err: errCollectNonSlice, Value: fieldValue(fld), Type: fv.Type()}
+		}
+	case DuplicateKeysLastWins:
+	}
+
+	return false, nil
+}
+
 func (d *decoder) arrayInterface(v reflect.Value) error {
 	var a = make([]map[string]any, 0)
 	var er error
@@ -220,26 +756,78 @@ func (d *decoder) arrayInterface(v reflect.Value) error {
 		}
 	}
 
+	if er == nil {
+		er = d.s.err
+	}
+
 	v.Set(reflect.ValueOf(a))
 
 	return er
 }
 
 func (d *decoder) objectInterface() (map[string]any, error) {
-	m := make(map[string]any)
-
 	flds, err := d.s.tuple()
 	if err != nil {
 		return nil, err
 	}
 
+	return d.mapInterface(flds)
+}
+
+// mapInterface decodes the tuple fields into a map. Nested tuples decoded
+// into maps and lists decoded into slices.
+func (d *decoder) mapInterface(flds []node) (map[string]any, error) {
+	flds, err := dedupe(flds, d.opts.duplicateKeys, d.s.pos)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]any)
+
 	for _, fld := range flds {
-		m[fld[idxKey]] = fld[idxVal]
+		if isEmpty(fld) {
+			continue
+		}
+
+		val, err := d.valueInterface(fld)
+		if err != nil {
+			return nil, err
+		}
+
+		if d.opts.duplicateKeys == DuplicateKeysCollect {
+			vals, _ := m[fld.key].([]any)
+			val = append(vals, val)
+		}
+
+		m[fld.key] = val
 	}
 
 	return m, nil
 }
 
+func (d *decoder) valueInterface(n node) (any, error) {
+	switch n.kind {
+	case nodeBare:
+		return true, nil
+	case nodeTuple:
+		return d.mapInterface(n.nodes)
+	case nodeList:
+		items := make([]any, 0, len(n.nodes))
+		for _, item := range n.nodes {
+			val, err := d.valueInterface(item)
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, val)
+		}
+
+		return items, nil
+	default:
+		return n.value, nil
+	}
+}
+
 // indirect walks down v until it gets to a non-pointer.
 // inspired by
 //
@@ -255,12 +843,175 @@ func indirect(v reflect.Value) reflect.Value {
 			break
 		}
 
+		// Allocate nil pointers, i.e. elements of a slice of pointers.
+		if v.IsNil() && v.CanSet() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
 		v = v.Elem()
 	}
 
 	return v
 }
 
+// setField sets the scanned field value to v. A bare key sets bool to true.
+// A nested tuple decoded into a struct and a list decoded into a slice or
+// an array.
+func (d *decoder) setField(v reflect.Value, fld node) error {
+	if v.CanAddr() && v.Addr().Type().Implements(optionalSetterType) {
+		o := v.Addr().Interface().(optionalSetter)
+
+		ov := reflect.New(o.optionalType()).Elem()
+		if err := d.setField(ov, fld); err != nil {
+			return err
+		}
+
+		o.setOptional(ov)
+
+		return nil
+	}
+
+	if v.Type() == tupleType {
+		return d.setObject(v, fld)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		// Allocate the pointer only when the field is present in a tuple.
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return d.setField(v.Elem(), fld)
+	case reflect.Struct, reflect.Map:
+		return d.setObject(v, fld)
+	case reflect.Interface:
+		return d.setInterface(v, fld)
+	case reflect.Slice, reflect.Array:
+		resetList(v)
+
+		_, err := d.appendList(v, 0, fld, 0)
+
+		return err
+	}
+
+	if fld.kind == nodeTuple || fld.kind == nodeList {
+		return &UnmarshalError{Value: fieldValue(fld), Type: v.Type()}
+	}
+
+	val := fieldValue(fld)
+	if isBare(fld) && isBool(v.Type()) {
+		val = "true"
+	}
+
+	return set(v, val)
+}
+
+// setObject decodes the nested tuple field into the Tuple, struct or map v.
+func (d *decoder) setObject(v reflect.Value, fld node) error {
+	if fld.kind != nodeTuple {
+		return &UnmarshalError{Value: fieldValue(fld), Type: v.Type()}
+	}
+
+	switch {
+	case v.Type() == tupleType:
+		return d.tupleFields(v, fld.nodes)
+	case v.Kind() == reflect.Struct:
+		return d.fields(v, fld.nodes)
+	}
+
+	return d.mapFields(v, fld.nodes)
+}
+
+// setInterface decodes the field into the interface v. A nested tuple is
+// decoded by its kind, see polymorphic. Other values are decoded into an empty
+// interface only.
+func (d *decoder) setInterface(v reflect.Value, fld node) error {
+	if fld.kind == nodeTuple {
+		return d.polymorphic(v, fld.nodes)
+	}
+
+	if v.NumMethod() > 0 {
+		return &UnmarshalError{Value: fieldValue(fld), Type: v.Type()}
+	}
+
+	val, err := d.valueInterface(fld)
+	if err != nil {
+		return err
+	}
+
+	v.Set(reflect.ValueOf(val))
+
+	return nil
+}
+
+// isList reports whether v is a slice or an array. A Tuple is not a list.
+func isList(v reflect.Value) bool {
+	return (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type() != tupleType
+}
+
+// resetList empties the slice v or sets zeros to the array v.
+func resetList(v reflect.Value) {
+	if v.Kind() == reflect.Slice {
+		v.Set(reflect.MakeSlice(v.Type(), 0, 1))
+	} else {
+		v.Set(reflect.Zero(v.Type()))
+	}
+}
+
+// appendList converts the scanned field value to the element type of the
+// list v and stores it after n elements of v. Every item of a list value and
+// every part of a plain value split by sep stored as a separate element.
+// Elements that do not fit into the array are omitted. It returns the number
+// of elements in the list.
+func (d *decoder) appendList(v reflect.Value, n int, fld node, sep rune) (int, error) {
+	elems := []node{fld}
+
+	switch {
+	case fld.kind == nodeList:
+		elems = fld.nodes
+	case fld.kind == nodeValue && sep != 0 && !fld.quoted:
+		elems = nil
+		for _, val := range strings.Split(fld.value, string(sep)) {
+			elems = append(elems, node{kind: nodeValue, key: fld.key, value: val})
+		}
+	}
+
+	for _, elem := range elems {
+		switch {
+		case v.Kind() == reflect.Slice:
+			ev := reflect.New(v.Type().Elem()).Elem()
+			if err := d.setField(ev, elem); err != nil {
+				return n, err
+			}
+
+			v.Set(reflect.Append(v, ev))
+		case n < v.Len():
+			if err := d.setField(v.Index(n), elem); err != nil {
+				return n, err
+			}
+		}
+
+		n++
+	}
+
+	return n, nil
+}
+
+// isBool reports whether t is a bool, a pointer to a bool or an Optional
+// bool.
+func isBool(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if reflect.PointerTo(t).Implements(optionalSetterType) {
+		return isBool(reflect.Zero(t).Interface().(optional).optionalType())
+	}
+
+	return t.Kind() == reflect.Bool
+}
+
 func set(v reflect.Value, value string) error {
 	switch v.Kind() {
 	case reflect.String:
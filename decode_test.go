@@ -54,7 +54,114 @@ type TFloats struct {
 }
 
 type TUnsupportedFldType struct {
-	A []string `tuples:"a"`
+	A complex128 `tuples:"a"`
+}
+
+type TPointers struct {
+	Name *string                  `tuples:"name"`
+	Age  *int                     `tuples:"age"`
+	Rate tuples.Optional[float64] `tuples:"rate"`
+	Kids *tuples.Optional[uint8]  `tuples:"kids"`
+}
+
+type TFlags struct {
+	ReadOnly bool                  `tuples:"ro"`
+	NoExec   *bool                 `tuples:"noexec"`
+	NoSuid   tuples.Optional[bool] `tuples:"nosuid"`
+	UID      int                   `tuples:"uid"`
+}
+
+type TTags struct {
+	Note string
+	Tags []string `tuples:"tag"`
+	Name string   `tuples:"name"`
+}
+
+type TSlices struct {
+	Ints  []int                    `tuples:"n"`
+	Ptrs  []*string                `tuples:"p"`
+	Flags []bool                   `tuples:"f"`
+	Opts  []tuples.Optional[uint8] `tuples:"o"`
+}
+
+type TLists struct {
+	Formats []string `tuples:"formats,sep=|"`
+	Size    [2]int   `tuples:"size,sep=x"`
+	Tags    []string `tuples:"tag"`
+}
+
+type TBadSep struct {
+	Tags []string `tuples:"tag,sep=="`
+}
+
+type TSize struct {
+	H int `tuples:"h"`
+	W int `tuples:"w"`
+}
+
+type TNested struct {
+	Size   TSize                  `tuples:"size"`
+	Thumb  *TSize                 `tuples:"thumb"`
+	Crop   tuples.Optional[TSize] `tuples:"crop"`
+	Sizes  []TSize                `tuples:"sizes"`
+	Format string                 `tuples:"f"`
+	Tags   []string               `tuples:"tags"`
+}
+
+type TOp interface {
+	Op() string
+}
+
+type TResize struct {
+	W int `tuples:"w"`
+	H int `tuples:"h"`
+}
+
+func (TResize) Op() string { return "resize" }
+
+type TCrop struct {
+	X int `tuples:"x"`
+	Y int `tuples:"y"`
+}
+
+func (*TCrop) Op() string { return "crop" }
+
+type TPipeline struct {
+	Name string `tuples:"name"`
+	Op   TOp    `tuples:"op"`
+	Meta any    `tuples:"meta"`
+}
+
+type TColumns struct {
+	H    []int `tuples:"h"`
+	W    []int `tuples:"w"`
+	Note string
+}
+
+type TMarkedColumns struct {
+	H []*int                 `tuples:"h"`
+	W []tuples.Optional[int] `tuples:"w"`
+	F []any                  `tuples:"f"`
+}
+
+type TPositional struct {
+	H      int      `tuples:"h"`
+	W      int      `tuples:"w"`
+	Format string   `tuples:"f"`
+	Tags   []string `tuples:"tags,sep=|"`
+}
+
+type TPinned struct {
+	Format string  `tuples:",pos=2"`
+	Size   TSize   `tuples:",pos=0"`
+	Thumb  *TSize  `tuples:",pos=1"`
+	Sizes  []TSize `tuples:",pos=3"`
+	Extra  []int   `tuples:",pos=4"`
+}
+
+type TPosCollision struct {
+	A string `tuples:"a"`
+	B string `tuples:",pos=0"`
 }
 
 type T2 struct {
@@ -69,6 +176,7 @@ type unmarshalTest struct {
 	out        any
 	err        error
 	withUnwrap bool
+	opts       []tuples.Option
 }
 
 var unmarshalTests = []unmarshalTest{
@@ -135,6 +243,469 @@ var unmarshalTests = []unmarshalTest{
 		},
 	},
 
+	// unmarshal to pointer and optional fields
+	{
+		in:  "name=John,age=0,rate=1.5,kids=2 age=3",
+		ptr: new([]TPointers),
+		out: []TPointers{
+			{Name: ptrTo("John"), Age: ptrTo(0), Rate: tuples.Some(1.5), Kids: ptrTo(tuples.Some[uint8](2))},
+			{Age: ptrTo(3)},
+		},
+	},
+	{
+		in:         "rate=a",
+		ptr:        new([]TPointers),
+		err:        &tuples.UnmarshalError{Value: "a", Type: reflect.TypeOf(float64(1))},
+		withUnwrap: true,
+	},
+
+	// unmarshal bare keys
+	{
+		in:   "ro,noexec,nosuid,uid=1000 uid=0,ro=false",
+		ptr:  new([]TFlags),
+		out:  []TFlags{{ReadOnly: true, NoExec: ptrTo(true), NoSuid: tuples.Some(true), UID: 1000}, {}},
+		opts: []tuples.Option{tuples.WithBareKeys()},
+	},
+	{
+		in:   "ro,uid=1000",
+		ptr:  new(any),
+		out:  []map[string]any{{"ro": true, "uid": "1000"}},
+		opts: []tuples.Option{tuples.WithBareKeys()},
+	},
+	{
+		in:         "uid",
+		ptr:        new([]TFlags),
+		err:        &tuples.UnmarshalError{Value: "", Type: reflect.TypeOf(1)},
+		withUnwrap: true,
+		opts:       []tuples.Option{tuples.WithBareKeys()},
+	},
+	{
+		in:  "ro,uid=1000",
+		ptr: new([]TFlags),
+		err: errors.New("tuples: scan failed: tuple #1 invalid field #1"),
+	},
+
+	// unmarshal duplicate keys
+	{
+		in:  "name=John,age=1,name=Bob",
+		ptr: new([]T),
+		out: []T{{Name: "Bob", Age: 1}},
+	},
+	{
+		in:   "name=John,age=1,name=Bob",
+		ptr:  new([]T),
+		out:  []T{{Name: "John", Age: 1}},
+		opts: []tuples.Option{tuples.WithDuplicateKeys(tuples.DuplicateKeysFirstWins)},
+	},
+	{
+		in:   "name=John,age=1 name=John,age=1,name=Bob",
+		ptr:  new([]T),
+		err:  &tuples.DuplicateKeyError{Key: "name", Tuple: 2},
+		opts: []tuples.Option{tuples.WithDuplicateKeys(tuples.DuplicateKeysError)},
+	},
+	{
+		in:   "name=John,tag=a,tag=b name=Bob tag=c",
+		ptr:  new([]TTags),
+		out:  []TTags{{Name: "John", Tags: []string{"a", "b"}}, {Name: "Bob"}, {Tags: []string{"c"}}},
+		opts: []tuples.Option{tuples.WithDuplicateKeys(tuples.DuplicateKeysCollect)},
+	},
+	{
+		in:         "tag=a,name=John,name=Bob",
+		ptr:        new([]TTags),
+		err:        &tuples.UnmarshalError{Value: "Bob", Type: reflect.TypeOf("")},
+		withUnwrap: true,
+		opts:       []tuples.Option{tuples.WithDuplicateKeys(tuples.DuplicateKeysCollect)},
+	},
+	{
+		in:   "a=1,b=2,a=3",
+		ptr:  new(any),
+		out:  []map[string]any{{"a": []any{"1", "3"}, "b": []any{"2"}}},
+		opts: []tuples.Option{tuples.WithDuplicateKeys(tuples.DuplicateKeysCollect)},
+	},
+	{
+		in:   "a=1,b=2,a=3 a=4",
+		ptr:  new([]map[string][]string),
+		out:  []map[string][]string{{"a": {"1", "3"}, "b": {"2"}}, {"a": {"4"}}},
+		opts: []tuples.Option{tuples.WithDuplicateKeys(tuples.DuplicateKeysCollect)},
+	},
+	{
+		in:         "a=1,a=2",
+		ptr:        new([]map[string]string),
+		err:        &tuples.UnmarshalError{Value: "2", Type: reflect.TypeOf("")},
+		withUnwrap: true,
+		opts:       []tuples.Option{tuples.WithDuplicateKeys(tuples.DuplicateKeysCollect)},
+	},
+	{
+		in:   "a=1,b=2,a=3",
+		ptr:  new(any),
+		out:  []map[string]any{{"a": "1", "b": "2"}},
+		opts: []tuples.Option{tuples.WithDuplicateKeys(tuples.DuplicateKeysFirstWins)},
+	},
+
+	// unmarshal repeated keys into slices
+	{
+		in:  "n=1,p=a,n=2,n=3,o=4 p=b,o=5",
+		ptr: new([]TSlices),
+		out: []TSlices{
+			{Ints: []int{1, 2, 3}, Ptrs: []*string{ptrTo("a")}, Opts: []tuples.Optional[uint8]{tuples.Some[uint8](4)}},
+			{Ptrs: []*string{ptrTo("b")}, Opts: []tuples.Optional[uint8]{tuples.Some[uint8](5)}},
+		},
+	},
+	{
+		in:   "f,f=false,n=1,n=2",
+		ptr:  new([]TSlices),
+		out:  []TSlices{{Ints: []int{1, 2}, Flags: []bool{true, false}}},
+		opts: []tuples.Option{tuples.WithBareKeys(), tuples.WithDuplicateKeys(tuples.DuplicateKeysError)},
+	},
+	{
+		in:   "tag=a,name=John,tag=b,name=Bob",
+		ptr:  new([]TTags),
+		out:  []TTags{{Name: "John", Tags: []string{"a", "b"}}},
+		opts: []tuples.Option{tuples.WithDuplicateKeys(tuples.DuplicateKeysFirstWins)},
+	},
+	{
+		in:         "n=1,n=a",
+		ptr:        new([]TSlices),
+		err:        &tuples.UnmarshalError{Value: "a", Type: reflect.TypeOf(1)},
+		withUnwrap: true,
+	},
+
+	// unmarshal lists packed into one value
+	{
+		in:  "formats=jpeg|png|webp,size=700x350,tag=a|b formats=gif,size=1x2x3,formats=bmp",
+		ptr: new([]TLists),
+		out: []TLists{
+			{Formats: []string{"jpeg", "png", "webp"}, Size: [2]int{700, 350}, Tags: []string{"a|b"}},
+			{Formats: []string{"gif", "bmp"}, Size: [2]int{1, 2}},
+		},
+	},
+	{
+		in:   "tag=a|b,size=1x2",
+		ptr:  new([]TLists),
+		out:  []TLists{{Tags: []string{"a", "b"}, Size: [2]int{1, 2}}},
+		opts: []tuples.Option{tuples.WithListDelimiter('|')},
+	},
+	{
+		in:  "tag=a",
+		ptr: new([]TBadSep),
+		err: errors.New("tuples: invalid delimiters: list delimiter equals fields, key-value or tuples delimiter"),
+	},
+	{
+		in:   "tag=a",
+		ptr:  new([]TLists),
+		err:  errors.New("tuples: invalid delimiters: list delimiter equals fields, key-value or tuples delimiter"),
+		opts: []tuples.Option{tuples.WithListDelimiter(',')},
+	},
+	{
+		in:         "size=1xa",
+		ptr:        new([]TLists),
+		err:        &tuples.UnmarshalError{Value: "a", Type: reflect.TypeOf(1)},
+		withUnwrap: true,
+	},
+
+	// unmarshal nested tuples
+	{
+		in:  "size=(h=700,w=350),f=jpeg,thumb=(h=7),crop=(w=1),sizes=[(h=1,w=2),(h=3,w=4)],tags=[a,b] sizes=(h=5),sizes=(w=6)",
+		ptr: new([]TNested),
+		out: []TNested{
+			{
+				Size:   TSize{H: 700, W: 350},
+				Thumb:  &TSize{H: 7},
+				Crop:   tuples.Some(TSize{W: 1}),
+				Sizes:  []TSize{{H: 1, W: 2}, {H: 3, W: 4}},
+				Format: "jpeg",
+				Tags:   []string{"a", "b"},
+			},
+			{Sizes: []TSize{{H: 5}, {W: 6}}},
+		},
+	},
+	{
+		in:  "size=(h=700,w=350),f=[jpeg,png] sizes=[(h=1),[2]]",
+		ptr: new(any),
+		out: []map[string]any{
+			{"size": map[string]any{"h": "700", "w": "350"}, "f": []any{"jpeg", "png"}},
+			{"sizes": []any{map[string]any{"h": "1"}, []any{"2"}}},
+		},
+	},
+	{
+		in:  "size=700",
+		ptr: new([]TNested),
+		err: &tuples.UnmarshalError{Value: "700", Type: reflect.TypeOf(TSize{})},
+	},
+	{
+		in:  "f=(h=1)",
+		ptr: new([]TNested),
+		err: &tuples.UnmarshalError{Value: "(h=1)", Type: reflect.TypeOf("")},
+	},
+	{
+		in:         "size=(h=a)",
+		ptr:        new([]TNested),
+		err:        &tuples.UnmarshalError{Value: "a", Type: reflect.TypeOf(1)},
+		withUnwrap: true,
+	},
+	{
+		in:   "size=(h=1,h=2)",
+		ptr:  new([]TNested),
+		err:  &tuples.DuplicateKeyError{Key: "h", Tuple: 1},
+		opts: []tuples.Option{tuples.WithDuplicateKeys(tuples.DuplicateKeysError)},
+	},
+
+	// unmarshal into a map keyed by the key field
+	{
+		in:  "id=small,h=100 id=large,h=900,f=png",
+		ptr: new(map[string]TFormat),
+		out: map[string]TFormat{
+			"small": {ID: "small", Height: 100},
+			"large": {ID: "large", Height: 900, Format: "png"},
+		},
+	},
+	{
+		in:  "id=small,h=100",
+		ptr: new(map[string]*TFormat),
+		out: map[string]*TFormat{"small": {ID: "small", Height: 100}},
+	},
+	{
+		in:  "",
+		ptr: new(map[string]TFormat),
+		out: map[string]TFormat{},
+	},
+	{
+		in:  "id=small,h=100 id=small,h=900",
+		ptr: new(map[string]TFormat),
+		err: &tuples.DuplicateKeyError{Key: "small", Tuple: 2},
+	},
+	{
+		in:  "id=small h=100",
+		ptr: new(map[string]TFormat),
+		err: &tuples.UnmarshalError{Value: "h=100", Type: reflect.TypeOf(TFormat{})},
+	},
+	{
+		in:  "id=65,h=100 id=66,h=900",
+		ptr: new(map[string]TIntKey),
+		out: map[string]TIntKey{
+			"65": {ID: 65, Height: 100},
+			"66": {ID: 66, Height: 900},
+		},
+	},
+	{
+		in:  "id=65,h=100",
+		ptr: new(map[int]TIntKey),
+		out: map[int]TIntKey{65: {ID: 65, Height: 100}},
+	},
+	{
+		in:  "id=65,h=100",
+		ptr: new(map[float64]TIntKey),
+		err: &tuples.UnmarshalError{Value: "65", Type: reflect.TypeOf(float64(0))},
+	},
+	{
+		in:  "name=John",
+		ptr: new(map[string]T),
+		err: &tuples.UnmarshalError{Value: "key", Type: reflect.TypeOf(T{})},
+	},
+
+	// unmarshal into columns
+	{
+		in:   "h=700,w=350 h=900,w=450 w=1",
+		ptr:  new(TColumns),
+		out:  TColumns{H: []int{700, 900, 0}, W: []int{350, 450, 1}},
+		opts: []tuples.Option{tuples.WithColumns()},
+	},
+	{
+		in:   "",
+		ptr:  new(TColumns),
+		out:  TColumns{H: []int{}, W: []int{}},
+		opts: []tuples.Option{tuples.WithColumns()},
+	},
+	{
+		in:  "h=1 w=2",
+		ptr: new(TMarkedColumns),
+		out: TMarkedColumns{
+			H: []*int{ptrTo(1), ptrTo(0)},
+			W: []tuples.Optional[int]{tuples.Some(0), tuples.Some(2)},
+			F: []any{nil, nil},
+		},
+		opts: []tuples.Option{tuples.WithColumns()},
+	},
+	{
+		in:  "h=1,f=png w=2",
+		ptr: new(TMarkedColumns),
+		out: TMarkedColumns{
+			H: []*int{ptrTo(1), nil},
+			W: []tuples.Optional[int]{{}, tuples.Some(2)},
+			F: []any{"png", nil},
+		},
+		opts: []tuples.Option{tuples.WithColumns(), tuples.WithMissing(tuples.MissingMark)},
+	},
+	{
+		in:         "h=1",
+		ptr:        new(TColumns),
+		err:        &tuples.UnmarshalError{Value: "missing", Type: reflect.TypeOf([]int{})},
+		withUnwrap: true,
+		opts:       []tuples.Option{tuples.WithColumns(), tuples.WithMissing(tuples.MissingMark)},
+	},
+	{
+		in:         "h=a",
+		ptr:        new(TColumns),
+		err:        &tuples.UnmarshalError{Value: "a", Type: reflect.TypeOf(1)},
+		withUnwrap: true,
+		opts:       []tuples.Option{tuples.WithColumns()},
+	},
+	{
+		in:   "h=1,h=2,w=3",
+		ptr:  new(TColumns),
+		out:  TColumns{H: []int{2}, W: []int{3}},
+		opts: []tuples.Option{tuples.WithColumns()},
+	},
+	{
+		in:  "h=1,w=2",
+		ptr: new(TColumns),
+		err: &tuples.UnmarshalError{Value: "array", Type: reflect.TypeOf(TColumns{})},
+	},
+	{
+		in:         "h=1,h=2",
+		ptr:        new(TColumns),
+		err:        &tuples.UnmarshalError{Value: "h", Type: reflect.TypeOf(TColumns{})},
+		withUnwrap: true,
+		opts:       []tuples.Option{tuples.WithColumns(), tuples.WithDuplicateKeys(tuples.DuplicateKeysCollect)},
+	},
+
+	// unmarshal into maps
+	{
+		in:  "h=700,w=350 f=png",
+		ptr: new([]map[string]string),
+		out: []map[string]string{{"h": "700", "w": "350"}, {"f": "png"}},
+	},
+	{
+		in:  "size=(h=700,w=350)",
+		ptr: new([]map[string]map[string]int),
+		out: []map[string]map[string]int{{"size": {"h": 700, "w": 350}}},
+	},
+	{
+		in:  "h=x",
+		ptr: new([]map[string]int),
+		err: &tuples.UnmarshalError{Value: "x", Type: reflect.TypeOf(1)},
+	},
+
+	// unmarshal quoted values
+	{
+		in:  `name="John Doe",age=23 name="a ""b"""`,
+		ptr: new([]T),
+		out: []T{{Name: "John Doe", Age: 23}, {Name: `a "b"`}},
+	},
+	{
+		in:  `formats="jpeg|png",size=1x2,tag=["a b",""]`,
+		ptr: new([]TLists),
+		out: []TLists{{Formats: []string{"jpeg|png"}, Size: [2]int{1, 2}, Tags: []string{"a b", ""}}},
+	},
+
+	// unmarshal positional tuples
+	{
+		in:   "700,350,jpeg,a|b 900,,png",
+		ptr:  new([]TPositional),
+		out:  []TPositional{{H: 700, W: 350, Format: "jpeg", Tags: []string{"a", "b"}}, {H: 900, Format: "png"}},
+		opts: []tuples.Option{tuples.WithPositional()},
+	},
+	{
+		in:   "(h=1,w=2),,jpeg,[(h=3,w=4)],[5,6]",
+		ptr:  new([]TPinned),
+		out:  []TPinned{{Format: "jpeg", Size: TSize{H: 1, W: 2}, Sizes: []TSize{{H: 3, W: 4}}, Extra: []int{5, 6}}},
+		opts: []tuples.Option{tuples.WithPositional()},
+	},
+	{
+		in:         "x",
+		ptr:        new([]TPosCollision),
+		err:        &tuples.UnmarshalError{Value: "tuple", Type: reflect.TypeOf(TPosCollision{})},
+		withUnwrap: true,
+		opts:       []tuples.Option{tuples.WithPositional()},
+	},
+	{
+		in:  "a=x",
+		ptr: new([]TPosCollision),
+		out: []TPosCollision{{A: "x"}},
+	},
+	{
+		in:   "700,350,jpeg,a,extra",
+		ptr:  new(any),
+		out:  []map[string]any{{"0": "700", "1": "350", "2": "jpeg", "3": "a", "4": "extra"}},
+		opts: []tuples.Option{tuples.WithPositional()},
+	},
+	{
+		in:   "1,2 3",
+		ptr:  new(TColumns),
+		out:  TColumns{H: []int{1, 3}, W: []int{2, 0}},
+		opts: []tuples.Option{tuples.WithColumns(), tuples.WithPositional()},
+	},
+	{
+		in:   "h=700",
+		ptr:  new([]TPositional),
+		err:  errors.New("tuples: scan failed: tuple #1 invalid field #1"),
+		opts: []tuples.Option{tuples.WithPositional()},
+	},
+
+	// unmarshal tuples with header
+	{
+		in:   "@w,f,h,tags 350,jpeg,700,a|b 450,,900",
+		ptr:  new([]TPositional),
+		out:  []TPositional{{H: 700, W: 350, Format: "jpeg", Tags: []string{"a", "b"}}, {H: 900, W: 450}},
+		opts: []tuples.Option{tuples.WithHeader()},
+	},
+	{
+		in:   "@h,size,f 700,(h=1,w=2) 900,,png",
+		ptr:  new(any),
+		out:  []map[string]any{{"h": "700", "size": map[string]any{"h": "1", "w": "2"}}, {"h": "900", "f": "png"}},
+		opts: []tuples.Option{tuples.WithHeader()},
+	},
+	{
+		in:   "@h,w 1,2 3",
+		ptr:  new(TColumns),
+		out:  TColumns{H: []int{1, 3}, W: []int{2, 0}},
+		opts: []tuples.Option{tuples.WithColumns(), tuples.WithHeader()},
+	},
+	{
+		in:   "@h,w",
+		ptr:  new([]TPositional),
+		out:  []TPositional{},
+		opts: []tuples.Option{tuples.WithHeader()},
+	},
+	{
+		in:   "@h,w 1,2,3",
+		ptr:  new([]TPositional),
+		err:  errors.New("tuples: scan failed: tuple #2 invalid field #3"),
+		opts: []tuples.Option{tuples.WithHeader()},
+	},
+	{
+		in:   "h=700,w=1 h=900",
+		ptr:  new([]TPositional),
+		err:  errors.New("tuples: scan failed: tuple #1 invalid header"),
+		opts: []tuples.Option{tuples.WithHeader()},
+	},
+	{
+		in:   "@h,h 1,2",
+		ptr:  new(any),
+		err:  errors.New("tuples: scan failed: tuple #1 invalid header"),
+		opts: []tuples.Option{tuples.WithHeader()},
+	},
+	{
+		in:   "h 1,2",
+		ptr:  new(TColumns),
+		err:  errors.New("tuples: scan failed: tuple #1 invalid header"),
+		opts: []tuples.Option{tuples.WithColumns(), tuples.WithHeader()},
+	},
+	{
+		in:   "@id,id 1,2",
+		ptr:  new(map[string]TFormat),
+		err:  errors.New("tuples: scan failed: tuple #1 invalid header"),
+		opts: []tuples.Option{tuples.WithHeader()},
+	},
+
+	// unmarshal with custom delimiters
+	{
+		in:   "name:John;age:23",
+		ptr:  new([]T),
+		out:  []T{{Name: "John", Age: 23}},
+		opts: []tuples.Option{tuples.WithFieldsDelimiter(';'), tuples.WithKeyValueDelimiter(':')},
+	},
+
 	// unmarshal to struct
 	{
 		in:  "name=John,lname=Doe,age=17",
@@ -152,16 +723,56 @@ var unmarshalTests = []unmarshalTest{
 		},
 	},
 
-	// // TODO: implement unmarshal a slice of interfaces
-	// // unmarshal to a slice of interfaces
-	// {
-	// 	in:  "name=John,lname=Doe,age=17 n=1,n8=-2,n16=3,n32=-4,n64=5",
-	// 	ptr: new([]any),
-	// 	out: []map[string]any{
-	// 		{"name": "John", "lname": "Doe", "age": "17"},
-	// 		{"n": "1", "n8": "-2", "n16": "3", "n32": "-4", "n64": "5"},
-	// 	},
-	// },
+	// unmarshal to a slice of interfaces
+	{
+		in:  "name=John,lname=Doe,age=17 n=1,n8=-2,n16=3,n32=-4,n64=5",
+		ptr: new([]any),
+		out: []any{
+			map[string]any{"name": "John", "lname": "Doe", "age": "17"},
+			map[string]any{"n": "1", "n8": "-2", "n16": "3", "n32": "-4", "n64": "5"},
+		},
+	},
+
+	// unmarshal to a slice of interfaces by the tuple kind
+	{
+		in:   "kind=resize,w=10 kind=crop,x=1,y=2",
+		ptr:  new([]TOp),
+		out:  []TOp{TResize{W: 10}, &TCrop{X: 1, Y: 2}},
+		opts: []tuples.Option{tuples.WithKind("resize", TResize{}), tuples.WithKind("crop", TCrop{})},
+	},
+	{
+		in:   "type=resize,w=10 type=crop,x=1",
+		ptr:  new([]any),
+		out:  []any{TResize{W: 10}, map[string]any{"type": "crop", "x": "1"}},
+		opts: []tuples.Option{tuples.WithKind("resize", TResize{}), tuples.WithKindKey("type")},
+	},
+	{
+		in:  "name=a,op=(kind=resize,h=5),meta=x name=b,meta=(a=1)",
+		ptr: new([]TPipeline),
+		out: []TPipeline{
+			{Name: "a", Op: TResize{H: 5}, Meta: "x"},
+			{Name: "b", Meta: map[string]any{"a": "1"}},
+		},
+		opts: []tuples.Option{tuples.WithKind("resize", TResize{})},
+	},
+	{
+		in:         "kind=crop,x=1",
+		ptr:        new([]TOp),
+		err:        &tuples.UnmarshalError{Value: "crop", Type: reflect.TypeOf((*TOp)(nil)).Elem()},
+		withUnwrap: true,
+	},
+	{
+		in:         "w=1",
+		ptr:        new([]TOp),
+		err:        &tuples.UnmarshalError{Value: "", Type: reflect.TypeOf((*TOp)(nil)).Elem()},
+		withUnwrap: true,
+	},
+	{
+		in:   "kind=t,name=John",
+		ptr:  new([]TOp),
+		err:  &tuples.UnmarshalError{Value: "t", Type: reflect.TypeOf((*TOp)(nil)).Elem()},
+		opts: []tuples.Option{tuples.WithKind("t", T{})},
+	},
 
 	// TODO: implement unmarshal to map
 	// // unmarshal to map of strings
@@ -220,7 +831,7 @@ var unmarshalTests = []unmarshalTest{
 	{
 		in:  "a=a",
 		ptr: new([]TUnsupportedFldType),
-		err: &tuples.UnmarshalUnsupportedTypeError{Type: reflect.TypeOf([]string{})},
+		err: &tuples.UnmarshalUnsupportedTypeError{Type: reflect.TypeOf(complex128(0))},
 	},
 
 	// invalid tuple expression
@@ -250,7 +861,7 @@ func TestUnmarshal(t *testing.T) {
 
 		got := reflect.New(typ.Elem())
 
-		if err := tuples.Unmarshal(in, got.Interface()); !eqErrors(err, tC.err) {
+		if err := tuples.Unmarshal(in, got.Interface(), tC.opts...); !eqErrors(err, tC.err) {
 			t.Errorf("#%d: unexpected Unmarshal() error: \ngot  %v\nwant %v", i, err, tC.err)
 			continue
 		} else if err != nil {
@@ -285,3 +896,194 @@ func TestUnmarshalToPrefilledSlice(t *testing.T) {
 		t.Errorf("Unmarshal() output cap: \ngot  %d\nwant %d", len(got), cp)
 	}
 }
+
+type TFormat struct {
+	ID     string `tuples:"id,key"`
+	Height int    `tuples:"h"`
+	Format string `tuples:"f"`
+}
+
+type TIntKey struct {
+	ID     int `tuples:"id,key"`
+	Height int `tuples:"h"`
+}
+
+type decodeModeTest struct {
+	desc string
+	in   string
+	ptr  any // pointer to prefilled value
+	out  any
+	err  error
+	mode tuples.DecodeMode
+}
+
+var decodeModeTests = []decodeModeTest{
+	{
+		desc: "Overwrite slice",
+		in:   "id=b,h=2",
+		ptr:  &[]TFormat{{ID: "a"}, {ID: "c"}},
+		out:  []TFormat{{ID: "b", Height: 2}},
+		mode: tuples.DecodeOverwrite,
+	},
+	{
+		desc: "Append to slice",
+		in:   "id=b,h=2 id=c",
+		ptr:  &[]TFormat{{ID: "a"}},
+		out:  []TFormat{{ID: "a"}, {ID: "b", Height: 2}, {ID: "c"}},
+		mode: tuples.DecodeAppend,
+	},
+	{
+		desc: "Append to empty slice",
+		in:   "",
+		ptr:  new([]TFormat),
+		out:  []TFormat{},
+		mode: tuples.DecodeAppend,
+	},
+	{
+		desc: "Append to array",
+		in:   "id=b id=c id=d",
+		ptr:  &[3]TFormat{{ID: "a"}},
+		out:  [3]TFormat{{ID: "a"}, {ID: "b"}, {ID: "c"}},
+		mode: tuples.DecodeAppend,
+	},
+	{
+		desc: "Merge into slice",
+		in:   "id=b,h=20 id=c,f=gif h=5 id=c,h=30 h=6",
+		ptr:  &[]TFormat{{ID: "a", Height: 1, Format: "png"}, {ID: "b", Height: 2, Format: "jpeg"}},
+		out: []TFormat{
+			{ID: "a", Height: 1, Format: "png"},
+			{ID: "b", Height: 20, Format: "jpeg"},
+			{ID: "c", Height: 30, Format: "gif"},
+			{Height: 5},
+			{Height: 6},
+		},
+		mode: tuples.DecodeMerge,
+	},
+	{
+		desc: "Merge into slice of pointers",
+		in:   "id=a,h=10 id=b",
+		ptr:  &[]*TFormat{{ID: "a", Format: "png"}},
+		out:  []*TFormat{{ID: "a", Height: 10, Format: "png"}, {ID: "b"}},
+		mode: tuples.DecodeMerge,
+	},
+	{
+		desc: "Merge into array",
+		in:   "id=a,h=10 id=b id=c",
+		ptr:  &[2]TFormat{{ID: "a", Format: "png"}},
+		out:  [2]TFormat{{ID: "a", Height: 10, Format: "png"}, {ID: "b"}},
+		mode: tuples.DecodeMerge,
+	},
+	{
+		desc: "Merge into map",
+		in:   "id=b,h=20 id=c,f=gif id=c,h=30",
+		ptr:  &map[string]TFormat{"a": {ID: "a", Height: 1}, "b": {ID: "b", Height: 2, Format: "jpeg"}},
+		out: map[string]TFormat{
+			"a": {ID: "a", Height: 1},
+			"b": {ID: "b", Height: 20, Format: "jpeg"},
+			"c": {ID: "c", Height: 30, Format: "gif"},
+		},
+		mode: tuples.DecodeMerge,
+	},
+	{
+		desc: "Append to map",
+		in:   "id=b,h=20",
+		ptr:  &map[string]TFormat{"a": {ID: "a", Height: 1}},
+		out:  map[string]TFormat{"a": {ID: "a", Height: 1}, "b": {ID: "b", Height: 20}},
+		mode: tuples.DecodeAppend,
+	},
+	{
+		desc: "Append existing key to map",
+		in:   "id=a,h=20",
+		ptr:  &map[string]TFormat{"a": {ID: "a", Height: 1}},
+		err:  &tuples.DuplicateKeyError{Key: "a", Tuple: 1},
+		mode: tuples.DecodeAppend,
+	},
+	{
+		desc: "Merge requires key field",
+		in:   "name=John",
+		ptr:  &[]T{{Name: "Bob"}},
+		err:  &tuples.UnmarshalError{Value: "key", Type: reflect.TypeOf(T{})},
+		mode: tuples.DecodeMerge,
+	},
+}
+
+func TestUnmarshalDecodeModes(t *testing.T) {
+	for tI, tC := range decodeModeTests {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := tuples.Unmarshal([]byte(tC.in), tC.ptr, tuples.WithDecodeMode(tC.mode))
+			if !eqErrors(err, tC.err) {
+				t.Fatalf("#%d: unexpected Unmarshal() error: \ngot  %v\nwant %v", tI, err, tC.err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if got := reflect.ValueOf(tC.ptr).Elem().Interface(); !reflect.DeepEqual(got, tC.out) {
+				t.Errorf("#%d: Unmarshal() output:\ngot  %+v\nwant %+v", tI, got, tC.out)
+			}
+		})
+	}
+}
+
+func TestColumnsRoundTrip(t *testing.T) {
+	type Cfg struct {
+		Tags  []string `tuples:"tag"`
+		Hosts []string `tuples:"host"`
+	}
+
+	in := Cfg{Tags: []string{"a", "b"}, Hosts: []string{"x", "y"}}
+
+	// Without columns the struct is one tuple with repeated keys.
+	b, err := tuples.Marshal(in)
+	if err != nil {
+		t.Fatalf("unexpected Marshal() error: %v", err)
+	}
+
+	if want := "tag=a,tag=b,host=x,host=y"; string(b) != want {
+		t.Errorf("Marshal() output:\ngot  %s\nwant %s", b, want)
+	}
+
+	var out Cfg
+
+	want := &tuples.UnmarshalError{Value: "array", Type: reflect.TypeOf(Cfg{})}
+	if err := tuples.Unmarshal(b, &out); !eqErrors(err, want) {
+		t.Errorf("unexpected Unmarshal() error: \ngot  %v\nwant %v", err, want)
+	}
+
+	if out, err = tuples.ParseOne[Cfg](string(b)); err != nil || !reflect.DeepEqual(out, in) {
+		t.Errorf("ParseOne() = %+v, %v, want %+v", out, err, in)
+	}
+
+	// With columns every slice index is one tuple.
+	if b, err = tuples.Marshal(in, tuples.WithColumns()); err != nil {
+		t.Fatalf("unexpected Marshal() error: %v", err)
+	}
+
+	if want := "tag=a,host=x tag=b,host=y"; string(b) != want {
+		t.Errorf("Marshal() output:\ngot  %s\nwant %s", b, want)
+	}
+
+	out = Cfg{}
+	if err := tuples.Unmarshal(b, &out, tuples.WithColumns()); err != nil || !reflect.DeepEqual(out, in) {
+		t.Errorf("Unmarshal() = %+v, %v, want %+v", out, err, in)
+	}
+}
+
+func TestKeyedMapRoundTrip(t *testing.T) {
+	in := "id=65,h=100 id=66,h=900"
+
+	var m map[string]TIntKey
+	if err := tuples.Unmarshal([]byte(in), &m); err != nil {
+		t.Fatalf("unexpected Unmarshal() error: %v", err)
+	}
+
+	b, err := tuples.Marshal(m)
+	if err != nil {
+		t.Fatalf("unexpected Marshal() error: %v", err)
+	}
+
+	if string(b) != in {
+		t.Errorf("Marshal() output:\ngot  %s\nwant %s", b, in)
+	}
+}
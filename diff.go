@@ -0,0 +1,274 @@
+package tuples
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ChangeKind describes how a tuple or a field changed between two tuples
+// strings.
+type ChangeKind int
+
+const (
+	// ChangeAdded is a tuple or a field present only in the new string.
+	ChangeAdded ChangeKind = iota
+	// ChangeRemoved is a tuple or a field present only in the old string.
+	ChangeRemoved
+	// ChangeModified is a tuple or a field present in both strings with
+	// different values.
+	ChangeModified
+)
+
+// FieldChange describes a changed field of a tuple. Old is empty for added
+// fields and New is empty for removed ones.
+type FieldChange struct {
+	Kind ChangeKind
+	Key  string
+	Old  string
+	New  string
+}
+
+// TupleChange describes a changed tuple. Pos is the tuple position, starting
+// from 1, in the old string, or in the new string for added tuples. Fields
+// are set for modified tuples only.
+type TupleChange struct {
+	Kind   ChangeKind
+	Pos    int
+	Old    Tuple
+	New    Tuple
+	Fields []FieldChange
+}
+
+// Changes describes the difference between two tuples strings, see Diff.
+type Changes struct {
+	Added    []TupleChange
+	Removed  []TupleChange
+	Modified []TupleChange
+}
+
+// Equal reports whether the tuples strings a and b have the same tuples.
+// Fields of a tuple and of nested tuples are compared regardless of their
+// order, values of a repeated key and list items are compared in order.
+// Tuples are compared in order, unless the WithIgnoreTupleOrder option is
+// set. Options are used to parse both strings. Equal returns an error when any
+// of the strings cannot be parsed.
+func Equal(a, b []byte, opts ...Option) (bool, error) {
+	ta, tb, err := parsePair(a, b, opts)
+	if err != nil {
+		return false, err
+	}
+
+	if len(ta) != len(tb) {
+		return false, nil
+	}
+
+	o := newOptions(opts)
+	so := o.scannerOpts()
+
+	ka, kb := make([]string, len(ta)), make([]string, len(tb))
+	for i := range ta {
+		ka[i], kb[i] = sortedTuple(ta[i], so), sortedTuple(tb[i], so)
+	}
+
+	if o.ignoreOrder {
+		slices.Sort(ka)
+		slices.Sort(kb)
+	}
+
+	return slices.Equal(ka, kb), nil
+}
+
+// Diff returns the tuples added, removed and modified in b comparing to a.
+// Tuples are paired by the value of the identity key set with the
+// WithMergeKey option, by equality with the WithIgnoreTupleOrder option, or by
+// their position otherwise. Paired tuples that are not equal, see Equal, are
+// modified. Diff does not report moved tuples. Options are used to parse both
+// strings.
+//
+// Usage:
+//
+//	changes, err := tuples.Diff(old, new, tuples.WithMergeKey("name"))
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Print(changes)
+func Diff(a, b []byte, opts ...Option) (*Changes, error) {
+	ta, tb, err := parsePair(a, b, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	o := newOptions(opts)
+	pairs := pairTuples(ta, tb, o)
+	paired := make([]bool, len(tb))
+	changes := &Changes{}
+
+	for i, j := range pairs {
+		if j < 0 {
+			changes.Removed = append(changes.Removed, TupleChange{Kind: ChangeRemoved, Pos: i + 1, Old: ta[i]})
+			continue
+		}
+
+		paired[j] = true
+
+		if flds := diffFields(ta[i], tb[j], o.scannerOpts()); len(flds) > 0 {
+			changes.Modified = append(changes.Modified, TupleChange{
+				Kind:   ChangeModified,
+				Pos:    i + 1,
+				Old:    ta[i],
+				New:    tb[j],
+				Fields: flds,
+			})
+		}
+	}
+
+	for j, t := range tb {
+		if !paired[j] {
+			changes.Added = append(changes.Added, TupleChange{Kind: ChangeAdded, Pos: j + 1, New: t})
+		}
+	}
+
+	return changes, nil
+}
+
+// Empty reports whether there are no changes.
+func (c *Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// String returns a readable text of the changes. Removed, modified and added
+// tuples are listed one per line, marked with "-", "~" and "+". Lines of
+// a modified tuple are followed by indented lines of its changed fields, i.e.
+// "  ~ f: jpeg -> png".
+func (c *Changes) String() string {
+	var b strings.Builder
+
+	for _, tc := range c.Removed {
+		fmt.Fprintf(&b, "- tuple #%d %s\n", tc.Pos, tc.Old)
+	}
+
+	for _, tc := range c.Modified {
+		fmt.Fprintf(&b, "~ tuple #%d %s\n", tc.Pos, tc.Old)
+
+		for _, fc := range tc.Fields {
+			switch fc.Kind {
+			case ChangeAdded:
+				fmt.Fprintf(&b, "  + %s\n", Tuple{{Key: fc.Key, Value: fc.New}})
+			case ChangeRemoved:
+				fmt.Fprintf(&b, "  - %s\n", Tuple{{Key: fc.Key, Value: fc.Old}})
+			default:
+				fmt.Fprintf(&b, "  ~ %s: %s -> %s\n", fc.Key, defaultOptions.quoteValue(fc.Old), defaultOptions.quoteValue(fc.New))
+			}
+		}
+	}
+
+	for _, tc := range c.Added {
+		fmt.Fprintf(&b, "+ tuple #%d %s\n", tc.Pos, tc.New)
+	}
+
+	return b.String()
+}
+
+func parsePair(a, b []byte, opts []Option) ([]Tuple, []Tuple, error) {
+	da, err := ParseDocument(a, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := ParseDocument(b, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return da.tuples, db.tuples, nil
+}
+
+// pairTuples returns the position of the paired tuple of tb for every tuple
+// of ta, or -1 when there is no pair.
+func pairTuples(ta, tb []Tuple, o options) []int {
+	pairs := make([]int, len(ta))
+	used := make([]bool, len(tb))
+
+	pair := func(i int, match func(Tuple) bool) {
+		pairs[i] = -1
+
+		for j, t := range tb {
+			if !used[j] && match(t) {
+				pairs[i], used[j] = j, true
+				return
+			}
+		}
+	}
+
+	for i, t := range ta {
+		switch {
+		case o.mergeKey != "":
+			id, ok := t.Lookup(o.mergeKey)
+			pair(i, func(u Tuple) bool { return ok && Where(o.mergeKey, id)(u) })
+		case o.ignoreOrder:
+			key := sortedTuple(t, o.scannerOpts())
+			pair(i, func(u Tuple) bool { return sortedTuple(u, o.scannerOpts()) == key })
+		case i < len(tb):
+			pairs[i] = i
+		default:
+			pairs[i] = -1
+		}
+	}
+
+	return pairs
+}
+
+// sortedTuple returns the text of t with fields of the tuple and of nested
+// tuples sorted by key, see writeCanonicalFields. Values of a repeated key
+// keep their order and plain values are kept as is.
+func sortedTuple(t Tuple, so scannerOptions) string {
+	var b strings.Builder
+	writeCanonicalFields(&b, tupleNodes(t, so), func(s string) string { return s })
+
+	return b.String()
+}
+
+// diffFields returns the changed fields of the tuple b comparing to a. Keys
+// are in the order of their first appearance in a and then in b. Values of
+// a repeated key are compared by their position, nested tuples regardless of
+// their fields order.
+func diffFields(a, b Tuple, so scannerOptions) []FieldChange {
+	keys := a.Keys()
+	for _, key := range b.Keys() {
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+
+	var changes []FieldChange
+
+	for _, key := range keys {
+		ov, nv := fieldsOf(a, key), fieldsOf(b, key)
+
+		for i := 0; i < max(len(ov), len(nv)); i++ {
+			switch {
+			case i >= len(nv):
+				changes = append(changes, FieldChange{Kind: ChangeRemoved, Key: key, Old: ov[i].Value})
+			case i >= len(ov):
+				changes = append(changes, FieldChange{Kind: ChangeAdded, Key: key, New: nv[i].Value})
+			case sortedTuple(ov[i:i+1], so) != sortedTuple(nv[i:i+1], so):
+				changes = append(changes, FieldChange{Kind: ChangeModified, Key: key, Old: ov[i].Value, New: nv[i].Value})
+			}
+		}
+	}
+
+	return changes
+}
+
+// fieldsOf returns the fields of t with the key.
+func fieldsOf(t Tuple, key string) Tuple {
+	var flds Tuple
+	for _, f := range t {
+		if f.Key == key {
+			flds = append(flds, f)
+		}
+	}
+
+	return flds
+}
@@ -0,0 +1,175 @@
+package tuples_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/antklim/tuples"
+)
+
+func TestEqual(t *testing.T) {
+	testCases := []struct {
+		desc string
+		a    string
+		b    string
+		opts []tuples.Option
+		want bool
+	}{
+		{desc: "same", a: "h=700,w=350", b: "h=700,w=350", want: true},
+		{desc: "fields order", a: "h=700,w=350 f=png", b: "w=350,h=700\n f=png", want: true},
+		{desc: "quoting", a: `f=png,note="a b"`, b: `note="a b",f="png"`, want: true},
+		{desc: "different value", a: "h=700,w=350", b: "h=700,w=351", want: false},
+		{desc: "missing field", a: "h=700,w=350", b: "h=700", want: false},
+		{desc: "tuples order", a: "h=700 h=900", b: "h=900 h=700", want: false},
+		{
+			desc: "ignored tuples order",
+			a:    "h=700,w=1 h=900",
+			b:    "h=900 w=1,h=700",
+			opts: []tuples.Option{tuples.WithIgnoreTupleOrder()},
+			want: true,
+		},
+		{
+			desc: "ignored tuples order with different tuples",
+			a:    "h=700 h=700",
+			b:    "h=700 h=900",
+			opts: []tuples.Option{tuples.WithIgnoreTupleOrder()},
+			want: false,
+		},
+		{desc: "repeated key order", a: "t=a,h=1,t=b", b: "h=1,t=a,t=b", want: true},
+		{desc: "repeated key values order", a: "t=a,t=b", b: "t=b,t=a", want: false},
+		{desc: "tuples count", a: "h=700", b: "h=700 h=700", want: false},
+		{desc: "empty", a: "", b: " ", want: true},
+		{desc: "nested fields order", a: "s=(a=1,b=(c=2,d=3))", b: "s=(b=(d=3,c=2),a=1)", want: true},
+		{desc: "nested different value", a: "s=(a=1,b=2)", b: "s=(b=2,a=3)", want: false},
+		{desc: "list items order", a: "l=[(a=1,b=2),x]", b: "l=[x,(b=2,a=1)]", want: false},
+		{desc: "list of nested tuples", a: "l=[(a=1,b=2),x]", b: "l=[(b=2,a=1),x]", want: true},
+		{desc: "quoted nested-looking value", a: `s="(a=1)"`, b: "s=(a=1)", want: false},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			got, err := tuples.Equal([]byte(tC.a), []byte(tC.b), tC.opts...)
+			if err != nil {
+				t.Fatalf("unexpected Equal() error: %v", err)
+			}
+
+			if got != tC.want {
+				t.Errorf("Equal() = %t, want %t", got, tC.want)
+			}
+		})
+	}
+}
+
+func TestEqualFails(t *testing.T) {
+	expected := errors.New("tuples: scan failed: tuple #1 invalid field #1")
+
+	if _, err := tuples.Equal([]byte("h=1"), []byte("h=(")); !eqErrors(err, expected) {
+		t.Errorf("Equal() error mismatch:\ngot  %v\nwant %v", err, expected)
+	}
+
+	if _, err := tuples.Diff([]byte("h=("), []byte("h=1")); !eqErrors(err, expected) {
+		t.Errorf("Diff() error mismatch:\ngot  %v\nwant %v", err, expected)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := "h=700,w=350,f=jpeg h=900,f=png,t=a,t=b h=400"
+	b := "w=350,h=700,f=jpeg h=900,f=webp,q=80,t=a h=400 h=100"
+
+	changes, err := tuples.Diff([]byte(a), []byte(b))
+	if err != nil {
+		t.Fatalf("unexpected Diff() error: %v", err)
+	}
+
+	expected := &tuples.Changes{
+		Added: []tuples.TupleChange{
+			{Kind: tuples.ChangeAdded, Pos: 4, New: tuples.Tuple{{Key: "h", Value: "100"}}},
+		},
+		Modified: []tuples.TupleChange{
+			{
+				Kind: tuples.ChangeModified,
+				Pos:  2,
+				Old:  tuples.Tuple{{Key: "h", Value: "900"}, {Key: "f", Value: "png"}, {Key: "t", Value: "a"}, {Key: "t", Value: "b"}},
+				New:  tuples.Tuple{{Key: "h", Value: "900"}, {Key: "f", Value: "webp"}, {Key: "q", Value: "80"}, {Key: "t", Value: "a"}},
+				Fields: []tuples.FieldChange{
+					{Kind: tuples.ChangeModified, Key: "f", Old: "png", New: "webp"},
+					{Kind: tuples.ChangeRemoved, Key: "t", Old: "b"},
+					{Kind: tuples.ChangeAdded, Key: "q", New: "80"},
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Diff() output:\ngot  %+v\nwant %+v", changes, expected)
+	}
+
+	text := `~ tuple #2 h=900,f=png,t=a,t=b
+  ~ f: png -> webp
+  - t=b
+  + q=80
++ tuple #4 h=100
+`
+	if out := changes.String(); out != text {
+		t.Errorf("String() output:\ngot  %s\nwant %s", out, text)
+	}
+}
+
+func TestDiffPairing(t *testing.T) {
+	testCases := []struct {
+		desc string
+		a    string
+		b    string
+		opts []tuples.Option
+		text string
+	}{
+		{
+			desc: "no changes",
+			a:    "h=700,w=350",
+			b:    "w=350,h=700",
+			text: "",
+		},
+		{
+			desc: "by position",
+			a:    "name=db,port=1 name=cache",
+			b:    "name=cache",
+			text: "- tuple #2 name=cache\n~ tuple #1 name=db,port=1\n  ~ name: db -> cache\n  - port=1\n",
+		},
+		{
+			desc: "by identity key",
+			a:    "name=db,port=1 name=cache host=x",
+			b:    `name=cache name=queue name=db,port=2,note="a b"`,
+			opts: []tuples.Option{tuples.WithMergeKey("name")},
+			text: "- tuple #3 host=x\n~ tuple #1 name=db,port=1\n  ~ port: 1 -> 2\n  + note=\"a b\"\n+ tuple #2 name=queue\n",
+		},
+		{
+			desc: "nested fields order",
+			a:    "s=(a=1,b=2),f=png",
+			b:    "s=(b=2,a=1),f=gif",
+			text: "~ tuple #1 s=(a=1,b=2),f=png\n  ~ f: png -> gif\n",
+		},
+		{
+			desc: "ignored tuples order",
+			a:    "h=700 h=900 h=400",
+			b:    "h=400 h=100 h=700",
+			opts: []tuples.Option{tuples.WithIgnoreTupleOrder()},
+			text: "- tuple #2 h=900\n+ tuple #2 h=100\n",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			changes, err := tuples.Diff([]byte(tC.a), []byte(tC.b), tC.opts...)
+			if err != nil {
+				t.Fatalf("unexpected Diff() error: %v", err)
+			}
+
+			if out := changes.String(); out != tC.text {
+				t.Errorf("String() output:\ngot  %q\nwant %q", out, tC.text)
+			}
+
+			if empty := changes.Empty(); empty != (tC.text == "") {
+				t.Errorf("Empty() = %t, want %t", empty, tC.text == "")
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package tuples
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// Document is a mutable list of tuples parsed from a tuples string. It keeps
+// the tuples and fields order, so an edited document is written back with
+// only the changed fields differing.
+type Document struct {
+	tuples []Tuple
+	opts   options
+	header []string // keys of the parsed header tuple
+}
+
+// ParseDocument parses the tuples string data into a Document. Options, i.e.
+// delimiters, are used for both parsing and writing the document. With the
+// WithHeader option the document is written with the header tuple, and with
+// the WithPositional option only values are written.
+func ParseDocument(data []byte, opts ...Option) (*Document, error) {
+	var d decoder
+	if err := d.init(data, opts...); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{tuples: make([]Tuple, 0), opts: d.opts}
+	if err := d.unmarshal(&doc.tuples); err != nil {
+		return nil, err
+	}
+
+	doc.header = d.s.header
+
+	return doc, nil
+}
+
+// Where returns a predicate that matches tuples with the key set to the
+// value, i.e. Where("h", "900").
+func Where(key, value string) func(Tuple) bool {
+	return func(t Tuple) bool {
+		v, ok := t.Lookup(key)
+		return ok && v == value
+	}
+}
+
+// Tuples returns the tuples of the document. The tuples are shared with the
+// document, so changes to them change the document.
+func (doc *Document) Tuples() []Tuple {
+	return doc.tuples
+}
+
+// Len returns the number of tuples in the document.
+func (doc *Document) Len() int {
+	return len(doc.tuples)
+}
+
+// Append adds tuples to the end of the document.
+func (doc *Document) Append(tuples ...Tuple) {
+	doc.tuples = append(doc.tuples, tuples...)
+}
+
+// Find returns the tuples that match the predicate.
+func (doc *Document) Find(match func(Tuple) bool) []Tuple {
+	var found []Tuple
+
+	for _, t := range doc.tuples {
+		if match(t) {
+			found = append(found, t)
+		}
+	}
+
+	return found
+}
+
+// Delete removes the tuples that match the predicate. It returns the number
+// of removed tuples.
+func (doc *Document) Delete(match func(Tuple) bool) int {
+	n := 0
+
+	for _, t := range doc.tuples {
+		if !match(t) {
+			doc.tuples[n] = t
+			n++
+		}
+	}
+
+	removed := len(doc.tuples) - n
+	doc.tuples = doc.tuples[:n]
+
+	return removed
+}
+
+// Update sets the key to the value in the tuples that match the predicate, see
+// Tuple.Set. It returns the number of updated tuples.
+//
+// Usage:
+//
+//	doc.Update(tuples.Where("h", "900"), "f", "webp")
+func (doc *Document) Update(match func(Tuple) bool, key, value string) int {
+	n := 0
+
+	for i := range doc.tuples {
+		if match(doc.tuples[i]) {
+			doc.tuples[i].Set(key, value)
+			n++
+		}
+	}
+
+	return n
+}
+
+// Bytes returns the tuples encoding of the document. Tuples without fields
+// are skipped. Header documents are written with the header tuple of the
+// parsed header keys followed by the keys of added fields. Positional
+// documents are written by position, missing positions are written as empty
+// fields, and keys that are not positions cause WriterError.
+func (doc *Document) Bytes() ([]byte, error) {
+	var b bytes.Buffer
+
+	w := &Writer{w: bufio.NewWriter(&b), opts: doc.opts}
+	if doc.opts.header {
+		if w.header = doc.headerKeys(); len(w.header) > 0 {
+			if err := w.writeHeader(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, t := range doc.tuples {
+		if len(t) == 0 {
+			continue
+		}
+
+		if err := doc.write(w, t); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func (doc *Document) write(w *Writer, t Tuple) error {
+	if !doc.opts.positional {
+		return w.Write(t)
+	}
+
+	keys, err := positions(t)
+	if err != nil {
+		return err
+	}
+
+	return w.writeValues(t, keys)
+}
+
+// headerKeys returns the parsed header keys followed by the keys of the
+// document tuples that are not in the header.
+func (doc *Document) headerKeys() []string {
+	keys := append([]string(nil), doc.header...)
+	seen := make(map[string]bool, len(keys))
+
+	for _, key := range keys {
+		seen[key] = true
+	}
+
+	for _, t := range doc.tuples {
+		for _, f := range t {
+			if !seen[f.Key] {
+				seen[f.Key] = true
+				keys = append(keys, f.Key)
+			}
+		}
+	}
+
+	return keys
+}
+
+// positions returns the positions of a positional tuple from the first one to
+// the last used, i.e. ["0" "1" "2"] for the keys "0" and "2".
+func positions(t Tuple) ([]string, error) {
+	last := -1
+
+	for _, f := range t {
+		pos, err := strconv.Atoi(f.Key)
+		if err != nil || pos < 0 || strconv.Itoa(pos) != f.Key {
+			return nil, &WriterError{fmt.Errorf("%w %q", errInvalidKey, f.Key)}
+		}
+
+		last = max(last, pos)
+	}
+
+	keys := make([]string, last+1)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+
+	return keys, nil
+}
+
+// Decode decodes the tuples of the document into the value pointed to by v,
+// the same way as Unmarshal with the document options. The stored tuples are
+// decoded as they are, without being encoded first.
+func (doc *Document) Decode(v any) error {
+	so := doc.opts.scannerOpts()
+
+	nodes := make([][]node, len(doc.tuples))
+	texts := make([]string, len(doc.tuples))
+
+	for i, t := range doc.tuples {
+		nodes[i], texts[i] = tupleNodes(t, so), t.String()
+	}
+
+	d := decoder{opts: doc.opts, s: newNodesScanner(nodes, texts, so)}
+
+	return d.unmarshal(v)
+}
+
+// tupleNodes returns the fields of the tuple t as scanned fields. Nested
+// tuples and lists are parsed, empty values are bare keys when bare keys are
+// allowed, and fields of positional tuples are keyed by position.
+func tupleNodes(t Tuple, so scannerOptions) []node {
+	nodes := make([]node, 0, len(t))
+
+	for _, f := range t {
+		n := node{kind: nodeValue, value: f.Value, quoted: f.Quoted}
+
+		if !f.Quoted && isStructured(f.Value, so) {
+			p := parser{s: f.Value, opts: so}
+			n, _ = p.value(0)
+		} else if so.bare && f.Value == "" {
+			n.kind = nodeBare
+		}
+
+		n.key, n.positional = f.Key, so.positional
+		nodes = append(nodes, n)
+	}
+
+	return nodes
+}
+
+// String returns the tuples encoding of the document. It returns an empty
+// string if the document cannot be encoded.
+func (doc *Document) String() string {
+	b, err := doc.Bytes()
+	if err != nil {
+		return ""
+	}
+
+	return string(b)
+}
@@ -0,0 +1,207 @@
+package tuples_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/antklim/tuples"
+)
+
+func TestDocument(t *testing.T) {
+	doc, err := tuples.ParseDocument([]byte(`h=700,w=350,f=jpeg h=900,w=450,f=png,note="a b" h=900,f=gif`))
+	if err != nil {
+		t.Fatalf("unexpected ParseDocument() error: %v", err)
+	}
+
+	if n := doc.Update(tuples.Where("h", "900"), "f", "webp"); n != 2 {
+		t.Errorf("Update() = %d, want 2", n)
+	}
+
+	if n := doc.Delete(tuples.Where("w", "350")); n != 1 {
+		t.Errorf("Delete() = %d, want 1", n)
+	}
+
+	doc.Append(tuples.Tuple{{Key: "h", Value: "100"}, {Key: "size", Value: "(w=1)"}}, nil)
+
+	if n := doc.Len(); n != 4 {
+		t.Errorf("Len() = %d, want 4", n)
+	}
+
+	found := doc.Find(tuples.Where("f", "webp"))
+	expected := []tuples.Tuple{
+		{{Key: "h", Value: "900"}, {Key: "w", Value: "450"}, {Key: "f", Value: "webp"}, {Key: "note", Value: "a b"}},
+		{{Key: "h", Value: "900"}, {Key: "f", Value: "webp"}},
+	}
+
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("Find() output:\ngot  %v\nwant %v", found, expected)
+	}
+
+	out := doc.String()
+	if want := `h=900,w=450,f=webp,note="a b" h=900,f=webp h=100,size=(w=1)`; out != want {
+		t.Errorf("String() output:\ngot  %s\nwant %s", out, want)
+	}
+}
+
+func TestDocumentOptions(t *testing.T) {
+	doc, err := tuples.ParseDocument([]byte("h:1;ro"), tuples.WithFieldsDelimiter(';'), tuples.WithKeyValueDelimiter(':'),
+		tuples.WithBareKeys())
+	if err != nil {
+		t.Fatalf("unexpected ParseDocument() error: %v", err)
+	}
+
+	doc.Tuples()[0].Set("h", "a;b")
+
+	b, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("unexpected Bytes() error: %v", err)
+	}
+
+	if want := `h:"a;b";ro`; string(b) != want {
+		t.Errorf("Bytes() output:\ngot  %s\nwant %s", b, want)
+	}
+}
+
+func TestParseDocumentFails(t *testing.T) {
+	_, err := tuples.ParseDocument([]byte("h=1 w"))
+
+	expected := errors.New("tuples: scan failed: tuple #2 invalid field #1")
+	if !eqErrors(err, expected) {
+		t.Errorf("ParseDocument() error mismatch:\ngot  %v\nwant %v", err, expected)
+	}
+}
+
+func TestDocumentLayout(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		opts []tuples.Option
+		edit func(*tuples.Document)
+		out  string
+	}{
+		{
+			desc: "header",
+			in:   "@h,w,f 700,350,jpeg 900,,png",
+			opts: []tuples.Option{tuples.WithHeader()},
+			out:  "@h,w,f 700,350,jpeg 900,,png",
+		},
+		{
+			desc: "header with added key",
+			in:   "@h,w,f 700,350,jpeg",
+			opts: []tuples.Option{tuples.WithHeader()},
+			edit: func(doc *tuples.Document) {
+				doc.Append(tuples.Tuple{{Key: "h", Value: "1"}, {Key: "note", Value: "a b"}})
+			},
+			out: `@h,w,f,note 700,350,jpeg, 1,,,"a b"`,
+		},
+		{
+			desc: "header only",
+			in:   "@h,w",
+			opts: []tuples.Option{tuples.WithHeader()},
+			out:  "@h,w",
+		},
+		{
+			desc: "positional",
+			in:   "700,,jpeg,(h=1) 900",
+			opts: []tuples.Option{tuples.WithPositional()},
+			out:  "700,,jpeg,(h=1) 900",
+		},
+		{
+			desc: "positional with set position",
+			in:   "700,350",
+			opts: []tuples.Option{tuples.WithPositional()},
+			edit: func(doc *tuples.Document) { doc.Update(tuples.Where("0", "700"), "3", "png") },
+			out:  "700,350,,png",
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			doc, err := tuples.ParseDocument([]byte(tC.in), tC.opts...)
+			if err != nil {
+				t.Fatalf("unexpected ParseDocument() error: %v", err)
+			}
+
+			if tC.edit != nil {
+				tC.edit(doc)
+			}
+
+			b, err := doc.Bytes()
+			if err != nil {
+				t.Fatalf("unexpected Bytes() error: %v", err)
+			}
+
+			if string(b) != tC.out {
+				t.Errorf("Bytes() output:\ngot  %s\nwant %s", b, tC.out)
+			}
+		})
+	}
+}
+
+func TestDocumentLayoutFails(t *testing.T) {
+	doc, err := tuples.ParseDocument([]byte("700,350"), tuples.WithPositional())
+	if err != nil {
+		t.Fatalf("unexpected ParseDocument() error: %v", err)
+	}
+
+	doc.Tuples()[0].Set("h", "1")
+
+	_, err = doc.Bytes()
+
+	expected := errors.New(`tuples: write failed: invalid key "h"`)
+	if !eqErrors(err, expected) {
+		t.Errorf("Bytes() error mismatch:\ngot  %v\nwant %v", err, expected)
+	}
+}
+
+func TestDocumentDecode(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		opts []tuples.Option
+		ptr  any
+		out  any
+	}{
+		{
+			desc: "header",
+			in:   "@w,f,h,tags 350,jpeg,700,a|b 450,,900",
+			opts: []tuples.Option{tuples.WithHeader()},
+			ptr:  new([]TPositional),
+			out:  []TPositional{{H: 700, W: 350, Format: "jpeg", Tags: []string{"a", "b"}}, {H: 900, W: 450}},
+		},
+		{
+			desc: "positional",
+			in:   "700,350,jpeg,a|b 900,,png",
+			opts: []tuples.Option{tuples.WithPositional()},
+			ptr:  new([]TPositional),
+			out:  []TPositional{{H: 700, W: 350, Format: "jpeg", Tags: []string{"a", "b"}}, {H: 900, Format: "png"}},
+		},
+		{
+			desc: "nested and bare",
+			in:   `size=(h=1,w=2),l=[a,b],n="(a=1)",ro`,
+			opts: []tuples.Option{tuples.WithBareKeys()},
+			ptr:  new(any),
+			out: []map[string]any{{
+				"size": map[string]any{"h": "1", "w": "2"}, "l": []any{"a", "b"}, "n": "(a=1)", "ro": true,
+			}},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			doc, err := tuples.ParseDocument([]byte(tC.in), tC.opts...)
+			if err != nil {
+				t.Fatalf("unexpected ParseDocument() error: %v", err)
+			}
+
+			if err := doc.Decode(tC.ptr); err != nil {
+				t.Fatalf("unexpected Decode() error: %v", err)
+			}
+
+			if out := reflect.ValueOf(tC.ptr).Elem().Interface(); !reflect.DeepEqual(out, tC.out) {
+				t.Errorf("Decode() output:\ngot  %v\nwant %v", out, tC.out)
+			}
+		})
+	}
+}
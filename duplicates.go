@@ -0,0 +1,66 @@
+package tuples
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DuplicateKeys describes how repeated keys of a tuple are handled.
+type DuplicateKeys int
+
+const (
+	// DuplicateKeysLastWins keeps the last value of a repeated key. It's the
+	// default policy.
+	DuplicateKeysLastWins DuplicateKeys = iota
+	// DuplicateKeysFirstWins keeps the first value of a repeated key.
+	DuplicateKeysFirstWins
+	// DuplicateKeysError fails decoding with a DuplicateKeyError.
+	DuplicateKeysError
+	// DuplicateKeysCollect keeps all values of a repeated key. Struct fields
+	// of the repeated keys must be slices, map values slices or interfaces.
+	//
+	// Repeated keys of slice struct fields are always collected, regardless
+	// of the policy.
+	DuplicateKeysCollect
+)
+
+var errCollectNonSlice = errors.New("collecting repeated key requires a slice")
+
+// DuplicateKeyError describes a repeated key found in a tuple.
+type DuplicateKeyError struct {
+	Key   string
+	Tuple int
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("tuples: tuple #%d duplicate key %q", e.Tuple, e.Key)
+}
+
+// dedupe applies the duplicate keys policy to the scanned tuple fields. It
+// returns the fields in their original order. Only the first occurrence of
+// a repeated key kept for DuplicateKeysFirstWins. All fields kept for the
+// other policies, the last value overwrites the previous ones on decoding.
+func dedupe(flds []node, policy DuplicateKeys, pos int) ([]node, error) {
+	if policy == DuplicateKeysLastWins || policy == DuplicateKeysCollect {
+		return flds, nil
+	}
+
+	seen := make(map[string]bool, len(flds))
+	deduped := flds[:0:0]
+
+	for _, fld := range flds {
+		key := fld.key
+		if !seen[key] {
+			seen[key] = true
+			deduped = append(deduped, fld)
+
+			continue
+		}
+
+		if policy == DuplicateKeysError {
+			return nil, &DuplicateKeyError{Key: key, Tuple: pos}
+		}
+	}
+
+	return deduped, nil
+}
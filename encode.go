@@ -6,6 +6,13 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	errListDelimiterValue = errors.New("list delimiter")
+	errKeyFieldType       = errors.New("invalid key field type")
 )
 
 const (
@@ -30,10 +37,46 @@ const (
 //
 // Only basic types supported as values, i.e string, int, float, boolean.
 // MarshalError returned in case, when unsupported type found.
-func Marshal(v any) ([]byte, error) {
-	var e encoder
+//
+// Pointer and Optional values are marshaled as the values they hold. Nil
+// pointers and unset Optional values are omitted.
+//
+// Slice and array values are marshaled as a repeated key per element, i.e.
+// "tag=a,tag=b", or as one value with elements separated by the list
+// delimiter, i.e. "tags=a|b", see WithListDelimiter. A single element that
+// contains the list delimiter is quoted, multiple such elements cause
+// MarshalError.
+//
+// Nested struct and map values are marshaled as nested tuples, i.e.
+// "size=(h=700,w=350)", and slices of them as lists of nested tuples, i.e.
+// "sizes=[(h=1,w=2),(h=3,w=4)]".
+//
+// A struct registered with RegisterKind or the WithKind option is marshaled
+// with the kind field first, i.e. "kind=resize,w=10".
+//
+// A map of structs with the key field, marked with the key tag option, is
+// marshaled as a list of tuples in the sorted map keys order. The key field
+// of every tuple is set to the map key, a string key field to the key text.
+// Other key fields of a kind different from the map key cause MarshalError.
+//
+// A struct of slices is marshaled as columns, one tuple per slice index, with
+// the WithColumns option.
+//
+// Values are marshaled without keys, in the order of the field positions, with
+// the WithPositional option, i.e. "700,350,jpeg".
+//
+// With the WithHeader option the keys are marshaled once, by the header tuple,
+// followed by values of every struct or map, i.e. "@h,w,f 700,350,jpeg".
+//
+// Options, i.e. delimiters, are the same as the reader options.
+func Marshal(v any, opts ...Option) ([]byte, error) {
+	e := encoder{opts: newOptions(opts)}
+
+	if err := e.opts.validate(); err != nil {
+		return nil, err
+	}
 
-	if err := e.encode(reflect.ValueOf(v)); err != nil {
+	if err := e.marshal(reflect.ValueOf(v)); err != nil {
 		return nil, err
 	}
 
@@ -55,19 +98,48 @@ func (e *MarshalError) Unwrap() error {
 }
 
 type encoder struct {
-	b bytes.Buffer
+	b     bytes.Buffer
+	opts  options
+	depth int // nesting level of the tuple being written
+}
+
+// marshal encodes the top level value. A map of structs with the key field
+// encoded as a list of tuples. A struct of slices encoded as columns when
+// requested. Values are encoded after the header tuple when requested.
+func (e *encoder) marshal(v reflect.Value) error {
+	v = unwrapElement(v)
+
+	if e.opts.header {
+		return e.headerTuples(v)
+	}
+
+	if v.Kind() == reflect.Map {
+		if kf, ok := mapKeyField(v.Type()); ok {
+			return e.keyedMap(v, kf)
+		}
+	}
+
+	if v.Kind() == reflect.Struct && e.opts.columns && isColumns(v.Type()) {
+		return e.columns(v)
+	}
+
+	return e.encode(v)
 }
 
 func (e *encoder) encode(v reflect.Value) error {
 	v = unwrapElement(v)
 
+	if v.IsValid() && v.Type() == tupleType {
+		return e.tuple(v)
+	}
+
 	switch v.Kind() {
 	case reflect.Struct:
 		return e.structObj(v)
 	case reflect.Map:
 		return e.mapObj(v)
 	case reflect.Slice, reflect.Array:
-		return e.array(v)
+		return e.array(v, tuplesDelimiter)
 	case reflect.Invalid, reflect.Chan, reflect.Func, reflect.UnsafePointer:
 		return nil
 	default:
@@ -76,13 +148,46 @@ func (e *encoder) encode(v reflect.Value) error {
 }
 
 func (e *encoder) structObj(v reflect.Value) error {
+	if e.opts.positional && e.depth == 0 {
+		return e.positionalStruct(v)
+	}
+
 	sf := cachedTypeFields(v.Type())
 
-	for i, fld := range sf.fields {
+	i := 0
+
+	// Write the kind of a registered struct, unless the struct has own field
+	// with the kind key.
+	if name, ok := e.opts.kindName(v.Type()); ok {
+		if _, ok := sf.fieldsByTag[e.opts.kindKey]; !ok {
+			n, err := e.writeField(e.opts.kindKey, reflect.ValueOf(name), i, 0)
+			if err != nil {
+				return err
+			}
+
+			i += n
+		}
+	}
+
+	for _, fld := range sf.fields {
+		// Fields tagged with a position only are not written as key-values.
+		if fld.tag == "" {
+			continue
+		}
+
 		key, val := fld.tag, v.FieldByName(fld.name)
-		if err := e.writeKeyVal(key, val, i); err != nil {
+
+		sep, err := e.opts.fieldListDelimiter(fld)
+		if err != nil {
+			return err
+		}
+
+		n, err := e.writeField(key, val, i, sep)
+		if err != nil {
 			return err
 		}
+
+		i += n
 	}
 
 	return nil
@@ -105,19 +210,125 @@ func (e *encoder) mapObj(v reflect.Value) error {
 		return keyVals[i].key < keyVals[j].key
 	})
 
-	for i, kv := range keyVals {
-		if err := e.writeKeyVal(kv.key, kv.val, i); err != nil {
+	if e.opts.positional && e.depth == 0 {
+		return e.positionalMap(keyVals)
+	}
+
+	i := 0
+	for _, kv := range keyVals {
+		n, err := e.writeMapField(kv.key, kv.val, i)
+		if err != nil {
 			return err
 		}
+
+		i += n
 	}
 
 	return nil
 }
 
-func (e *encoder) array(v reflect.Value) error {
-	for i := 0; i < v.Len(); i++ {
-		if i > 0 {
+// writeMapField writes a map entry as a tuple field. Lists of arbitrary
+// values, i.e. []any decoded from "l=[a,b]", are written in brackets, so they
+// are read back as lists. Other values are written by writeField.
+func (e *encoder) writeMapField(key string, val reflect.Value, keyIdx int) (int, error) {
+	uv := unwrapElement(val)
+	if !isList(uv) || uv.Type().Elem().Kind() != reflect.Interface {
+		return e.writeField(key, val, keyIdx, e.opts.listDelimiter)
+	}
+
+	if emptyList(uv) {
+		return 0, nil
+	}
+
+	return 1, e.writeKeyVal(key, val, keyIdx)
+}
+
+// keyedMap writes structs of the map v as tuples in the order of sorted map
+// keys. The key field of a struct is set to the map key.
+func (e *encoder) keyedMap(v reflect.Value, kf field) error {
+	keyVals := make([]keyVal, 0, v.Len())
+	for _, mapKey := range v.MapKeys() {
+		keyVals = append(keyVals, keyVal{fmt.Sprint(mapKey.Interface()), mapKey})
+	}
+
+	sort.SliceStable(keyVals, func(i, j int) bool {
+		return keyVals[i].key < keyVals[j].key
+	})
+
+	n := 0
+	for _, kv := range keyVals {
+		elem := unwrapElement(v.MapIndex(kv.val))
+		if !elem.IsValid() {
+			continue
+		}
+
+		// Copy the struct to set its key field.
+		st := reflect.New(elem.Type()).Elem()
+		st.Set(elem)
+
+		if err := setKeyField(st.FieldByName(kf.name), kv.val); err != nil {
+			return err
+		}
+
+		if n > 0 {
 			if _, err := e.b.WriteRune(tuplesDelimiter); err != nil {
+				return &MarshalError{err}
+			}
+		}
+
+		if err := e.structObj(st); err != nil {
+			return err
+		}
+
+		n++
+	}
+
+	return nil
+}
+
+// setKeyField sets the key field kfv to the map key mk. A string key field is
+// set to the text of the key, i.e. "65" for 65, and a string key is parsed
+// into other key fields, i.e. 65 for "65". Other key fields must be of the key
+// kind.
+func setKeyField(kfv, mk reflect.Value) error {
+	switch {
+	case kfv.Kind() == reflect.String:
+		kfv.SetString(fmt.Sprint(mk.Interface()))
+	case mk.Kind() == reflect.String:
+		if err := set(kfv, mk.String()); err != nil {
+			return &MarshalError{fmt.Errorf("%w: %q map key for %s key field", errKeyFieldType, mk.String(), kfv.Type())}
+		}
+	case kfv.Kind() == mk.Kind() && mk.Type().ConvertibleTo(kfv.Type()):
+		kfv.Set(mk.Convert(kfv.Type()))
+	default:
+		return &MarshalError{fmt.Errorf("%w: %s map key for %s key field", errKeyFieldType, mk.Type(), kfv.Type())}
+	}
+
+	return nil
+}
+
+// mapKeyField returns the key field of the map element type when it is
+// a struct or a pointer to a struct with the key field.
+func mapKeyField(t reflect.Type) (field, bool) {
+	et := indirectType(t.Elem())
+	if et.Kind() != reflect.Struct {
+		return field{}, false
+	}
+
+	return cachedTypeFields(et).keyField()
+}
+
+// array writes elements of v separated by the delimiter. Omitted elements are
+// skipped.
+func (e *encoder) array(v reflect.Value, delim rune) error {
+	n := 0
+	for i := 0; i < v.Len(); i++ {
+		if omitted(v.Index(i)) {
+			continue
+		}
+
+		if n > 0 {
+			if _, err := e.b.WriteRune(delim); err != nil {
 				return err
 			}
 		}
@@ -125,6 +336,52 @@ func (e *encoder) array(v reflect.Value) error {
 		if err := e.encode(v.Index(i)); err != nil {
 			return err
 		}
+
+		n++
+	}
+
+	return nil
+}
+
+// sepList writes the list v as one value with elements separated by sep, i.e.
+// "jpeg|png". A single element that contains sep is quoted, so it's read back
+// as one element. It returns MarshalError when one of multiple elements
+// contains sep.
+func (e *encoder) sepList(v reflect.Value, sep rune) error {
+	elems := 0
+	for i := 0; i < v.Len(); i++ {
+		if !omitted(v.Index(i)) {
+			elems++
+		}
+	}
+
+	n := 0
+	for i := 0; i < v.Len(); i++ {
+		if omitted(v.Index(i)) {
+			continue
+		}
+
+		if n > 0 {
+			if _, err := e.b.WriteRune(sep); err != nil {
+				return &MarshalError{err}
+			}
+		}
+
+		start := e.b.Len()
+		if err := e.encode(v.Index(i)); err != nil {
+			return err
+		}
+
+		if elem := string(e.b.Bytes()[start:]); strings.ContainsRune(elem, sep) && elem[0] != valueQuote {
+			if elems > 1 {
+				return &MarshalError{fmt.Errorf("%w %q in list element %q", errListDelimiterValue, sep, elem)}
+			}
+
+			e.b.Truncate(start)
+			e.b.WriteString(quote(elem))
+		}
+
+		n++
 	}
 
 	return nil
@@ -132,6 +389,9 @@ func (e *encoder) array(v reflect.Value) error {
 
 func (e *encoder) value(v reflect.Value) error {
 	elem := fmt.Sprint(v.Interface())
+	if v.Kind() == reflect.String {
+		elem = e.opts.quoteValue(elem)
+	}
 
 	if _, err := e.b.WriteString(elem); err != nil {
 		return &MarshalError{err}
@@ -140,19 +400,235 @@ func (e *encoder) value(v reflect.Value) error {
 	return nil
 }
 
+// writeField writes a tuple field with the key and value. A slice or array
+// value written as a repeated key per element, or as one value with elements
+// separated by sep when sep set. Nothing written for omitted values. It
+// returns the number of written fields.
+func (e *encoder) writeField(key string, val reflect.Value, keyIdx int, sep rune) (int, error) {
+	if omitted(val) || e.omittedBare(val) {
+		return 0, nil
+	}
+
+	uv := unwrapElement(val)
+	if !isList(uv) || objectList(uv) {
+		if isList(uv) && emptyList(uv) {
+			return 0, nil
+		}
+
+		return 1, e.writeKeyVal(key, val, keyIdx)
+	}
+
+	if sep != 0 {
+		if emptyList(uv) {
+			return 0, nil
+		}
+
+		if err := e.writeKey(key, keyIdx, false); err != nil {
+			return 0, &MarshalError{err}
+		}
+
+		return 1, e.sepList(uv, sep)
+	}
+
+	n := 0
+	for i := 0; i < uv.Len(); i++ {
+		w, err := e.writeField(key, uv.Index(i), keyIdx+n, 0)
+		if err != nil {
+			return n, err
+		}
+
+		n += w
+	}
+
+	return n, nil
+}
+
 func (e *encoder) writeKeyVal(key string, val reflect.Value, keyIdx int) error {
-	if err := e.writeKey(key, keyIdx); err != nil {
+	bare := e.bare(val)
+
+	if err := e.writeKey(key, keyIdx, bare); err != nil {
 		return &MarshalError{err}
 	}
 
-	err := e.encode(val)
+	if bare {
+		return nil
+	}
+
+	uv := unwrapElement(val)
+
+	switch {
+	case isObject(uv):
+		return e.nested(uv)
+	case isList(uv):
+		return e.nestedList(uv)
+	}
+
+	err := e.encode(uv)
 
 	return err
 }
 
-func (e *encoder) writeKey(key string, keyIdx int) error {
+// nested writes a struct or a map as a nested tuple, i.e. "(h=700,w=350)".
+func (e *encoder) nested(v reflect.Value) error {
+	if _, err := e.b.WriteRune(tupleOpen); err != nil {
+		return &MarshalError{err}
+	}
+
+	e.depth++
+	err := e.encode(v)
+	e.depth--
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := e.b.WriteRune(tupleClose); err != nil {
+		return &MarshalError{err}
+	}
+
+	return nil
+}
+
+// nestedList writes a list of structs or maps as a list of nested tuples,
+// i.e. "[(h=1,w=2),(h=3,w=4)]", and a list of other values as a list of
+// values, i.e. "[a,b]". Omitted elements are skipped.
+func (e *encoder) nestedList(v reflect.Value) error {
+	if _, err := e.b.WriteRune(listOpen); err != nil {
+		return &MarshalError{err}
+	}
+
+	n := 0
+	for i := 0; i < v.Len(); i++ {
+		elem := unwrapElement(v.Index(i))
+		if !elem.IsValid() {
+			continue
+		}
+
+		if n > 0 {
+			if _, err := e.b.WriteRune(e.opts.fieldsDelimiter); err != nil {
+				return &MarshalError{err}
+			}
+		}
+
+		if err := e.listElem(elem); err != nil {
+			return err
+		}
+
+		n++
+	}
+
+	if _, err := e.b.WriteRune(listClose); err != nil {
+		return &MarshalError{err}
+	}
+
+	return nil
+}
+
+// listElem writes an element of a bracketed list. Structs and maps are written
+// as nested tuples, lists as nested lists and other values are quoted when
+// needed.
+func (e *encoder) listElem(v reflect.Value) error {
+	switch {
+	case isObject(v):
+		return e.nested(v)
+	case isList(v):
+		return e.nestedList(v)
+	}
+
+	return e.value(v)
+}
+
+// positionalStruct writes values of the struct v without keys in the order
+// of the field positions, i.e. "700,350,jpeg". Omitted values and positions
+// without a field are written as empty fields.
+func (e *encoder) positionalStruct(v reflect.Value) error {
+	sf := cachedTypeFields(v.Type())
+	if sf.posErr != nil {
+		return &MarshalError{sf.posErr}
+	}
+
+	for pos := 0; pos < sf.positions(); pos++ {
+		if pos > 0 {
+			if _, err := e.b.WriteRune(e.opts.fieldsDelimiter); err != nil {
+				return &MarshalError{err}
+			}
+		}
+
+		idx, ok := sf.fieldsByPos[strconv.Itoa(pos)]
+		if !ok {
+			continue
+		}
+
+		fld := sf.fields[idx]
+
+		sep, err := e.opts.fieldListDelimiter(fld)
+		if err != nil {
+			return err
+		}
+
+		if err := e.positionalValue(v.FieldByName(fld.name), sep); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// positionalMap writes the map values without keys in the order of sorted
+// map keys.
+func (e *encoder) positionalMap(keyVals []keyVal) error {
+	for i, kv := range keyVals {
+		if i > 0 {
+			if _, err := e.b.WriteRune(e.opts.fieldsDelimiter); err != nil {
+				return &MarshalError{err}
+			}
+		}
+
+		if err := e.positionalValue(kv.val, e.opts.listDelimiter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// positionalValue writes a value of a positional tuple. A list is written as
+// one value with elements separated by sep, or as a bracketed list, i.e.
+// "[a,b]", when sep is not set.
+func (e *encoder) positionalValue(val reflect.Value, sep rune) error {
+	uv := unwrapElement(val)
+
+	switch {
+	case !uv.IsValid(), isList(uv) && emptyList(uv):
+		return nil
+	case isObject(uv):
+		return e.nested(uv)
+	case !isList(uv):
+		return e.encode(uv)
+	case objectList(uv):
+		return e.nestedList(uv)
+	case sep != 0:
+		return e.array(uv, sep)
+	}
+
+	if _, err := e.b.WriteRune(listOpen); err != nil {
+		return &MarshalError{err}
+	}
+
+	if err := e.array(uv, e.opts.fieldsDelimiter); err != nil {
+		return err
+	}
+
+	if _, err := e.b.WriteRune(listClose); err != nil {
+		return &MarshalError{err}
+	}
+
+	return nil
+}
+
+func (e *encoder) writeKey(key string, keyIdx int, bare bool) error {
 	if keyIdx > 0 {
-		if _, err := e.b.WriteRune(fieldsDelimiter); err != nil {
+		if _, err := e.b.WriteRune(e.opts.fieldsDelimiter); err != nil {
 			return err
 		}
 	}
@@ -161,13 +637,29 @@ func (e *encoder) writeKey(key string, keyIdx int) error {
 		return err
 	}
 
-	if _, err := e.b.WriteRune(keyValDelimiter); err != nil {
+	if bare {
+		return nil
+	}
+
+	if _, err := e.b.WriteRune(e.opts.keyValDelimiter); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// bare reports whether val should be written as a bare key, i.e. it is
+// a bool value and bare keys are enabled.
+func (e *encoder) bare(val reflect.Value) bool {
+	return e.opts.bareKeys && unwrapElement(val).Kind() == reflect.Bool
+}
+
+// omittedBare reports whether val is a false bool value that is left out
+// when bare keys are enabled.
+func (e *encoder) omittedBare(val reflect.Value) bool {
+	return e.bare(val) && !unwrapElement(val).Bool()
+}
+
 // keyVal stores map's key-value for further processing. Currently it's
 // used for sorting map by key to guarantee encoding output.
 type keyVal struct {
@@ -176,9 +668,58 @@ type keyVal struct {
 }
 
 func unwrapElement(v reflect.Value) reflect.Value {
-	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
-		v = v.Elem()
+	for {
+		switch v.Kind() {
+		case reflect.Interface, reflect.Pointer:
+			v = v.Elem()
+			continue
+		}
+
+		o, ok := asOptional(v)
+		if !ok {
+			return v
+		}
+
+		val, valid := o.optionalValue()
+		if !valid {
+			return reflect.Value{}
+		}
+
+		v = reflect.ValueOf(val)
 	}
+}
+
+// isObject reports whether v is marshaled as a tuple, i.e. it is a struct or
+// a map.
+func isObject(v reflect.Value) bool {
+	return v.Kind() == reflect.Struct || v.Kind() == reflect.Map || (v.IsValid() && v.Type() == tupleType)
+}
+
+// objectList reports whether v is a list of structs or maps. The first not
+// omitted element defines the list type.
+func objectList(v reflect.Value) bool {
+	for i := 0; i < v.Len(); i++ {
+		if elem := unwrapElement(v.Index(i)); elem.IsValid() {
+			return isObject(elem)
+		}
+	}
+
+	return false
+}
+
+// emptyList reports whether all elements of the list v are omitted.
+func emptyList(v reflect.Value) bool {
+	for i := 0; i < v.Len(); i++ {
+		if !omitted(v.Index(i)) {
+			return false
+		}
+	}
+
+	return true
+}
 
-	return v
+// omitted reports whether v has nothing to marshal, i.e. it is a nil pointer,
+// a nil interface or an unset Optional.
+func omitted(v reflect.Value) bool {
+	return !unwrapElement(v).IsValid()
 }
@@ -26,10 +26,83 @@ type T4 struct {
 	F3 int64 `tuples:"fld5"`
 }
 
+type T5 struct {
+	Name *string                `tuples:"name"`
+	Age  tuples.Optional[int]   `tuples:"age"`
+	Kids *tuples.Optional[bool] `tuples:"kids"`
+}
+
+type T6 struct {
+	ReadOnly bool  `tuples:"ro"`
+	NoExec   *bool `tuples:"noexec"`
+	UID      int   `tuples:"uid"`
+}
+
+type T7 struct {
+	Name string   `tuples:"name"`
+	Tags []string `tuples:"tag"`
+	Ns   [2]*int  `tuples:"n"`
+}
+
+type T8 struct {
+	Formats []string   `tuples:"formats,sep=|"`
+	Size    [2]int     `tuples:"size,sep=x"`
+	Tags    []*string  `tuples:"tag"`
+	Empty   []*float64 `tuples:"empty,sep=|"`
+}
+
+type T9Size struct {
+	H int `tuples:"h"`
+	W int `tuples:"w"`
+}
+
+type T9 struct {
+	Size   T9Size                  `tuples:"size"`
+	Thumb  *T9Size                 `tuples:"thumb"`
+	Crop   tuples.Optional[T9Size] `tuples:"crop"`
+	Sizes  []*T9Size               `tuples:"sizes"`
+	Format string                  `tuples:"f"`
+}
+
+type T10 struct {
+	Height int    `tuples:"h"`
+	ID     string `tuples:"id,key"`
+}
+
+type T10Int struct {
+	ID     int8   `tuples:"id,key"`
+	Format string `tuples:"f"`
+}
+
+type T11Kind struct {
+	Kind string `tuples:"kind"`
+	N    int    `tuples:"n"`
+}
+
+type T12Columns struct {
+	H []int                  `tuples:"h"`
+	W []*int                 `tuples:"w"`
+	F []tuples.Optional[int] `tuples:"f"`
+}
+
+type T13Positional struct {
+	H      int      `tuples:"h"`
+	W      *int     `tuples:"w"`
+	Tags   []string `tuples:",pos=2"`
+	Format string   `tuples:"f"`
+	Sizes  []T9Size `tuples:",pos=5"`
+}
+
+type T13PosCollision struct {
+	A string `tuples:"a"`
+	B string `tuples:",pos=0"`
+}
+
 type marshalTest struct {
-	in  any
-	out string
-	err error
+	in   any
+	out  string
+	err  error
+	opts []tuples.Option
 }
 
 var marshalTests = []marshalTest{
@@ -84,6 +157,211 @@ var marshalTests = []marshalTest{
 		out: "foo=hey,baaar=0",
 	},
 
+	// output pointers and optional values, omit nil and unset ones
+	{
+		in:  T5{Name: ptrTo("Bob"), Age: tuples.Some(0), Kids: ptrTo(tuples.Some(true))},
+		out: "name=Bob,age=0,kids=true",
+	},
+	{
+		in:  T5{Kids: &tuples.Optional[bool]{}},
+		out: "",
+	},
+	{
+		in:  []T5{{Age: tuples.Some(3)}, {Name: ptrTo("Bob")}},
+		out: "age=3 name=Bob",
+	},
+	{
+		in:  map[string]any{"a": nil, "b": (*int)(nil), "c": tuples.Some("x")},
+		out: "c=x",
+	},
+
+	// output bare keys
+	{
+		in:   []T6{{ReadOnly: true, NoExec: ptrTo(false), UID: 1000}, {NoExec: ptrTo(true)}},
+		out:  "ro,uid=1000 noexec,uid=0",
+		opts: []tuples.Option{tuples.WithBareKeys()},
+	},
+	{
+		in:   map[string]any{"rw": true, "sync": false},
+		out:  "rw",
+		opts: []tuples.Option{tuples.WithBareKeys()},
+	},
+
+	// output slices and arrays as repeated keys
+	{
+		in:  []T7{{Name: "a", Tags: []string{"x", "y"}, Ns: [2]*int{ptrTo(1), ptrTo(2)}}, {Name: "b", Ns: [2]*int{nil, ptrTo(3)}}},
+		out: "name=a,tag=x,tag=y,n=1,n=2 name=b,n=3",
+	},
+	{
+		in:  map[string]any{"a": []int{1, 2}, "b": []string{}},
+		out: "a=1,a=2",
+	},
+	{
+		in:  map[string]any{"l": []any{"a", []any{"b c", 1}, map[string]any{"h": 1}}, "e": []any{}},
+		out: `l=[a,["b c",1],(h=1)]`,
+	},
+
+	// output lists packed into one value
+	{
+		in:  T8{Formats: []string{"jpeg", "png"}, Size: [2]int{7, 3}, Tags: []*string{ptrTo("a"), nil, ptrTo("b")}, Empty: []*float64{nil}},
+		out: "formats=jpeg|png,size=7x3,tag=a,tag=b",
+	},
+	{
+		in:   T8{Tags: []*string{ptrTo("a"), nil, ptrTo("b")}},
+		out:  "size=0x0,tag=a;b",
+		opts: []tuples.Option{tuples.WithListDelimiter(';')},
+	},
+	{
+		in:  T8{Formats: []string{"jpeg|png"}, Size: [2]int{7, 3}},
+		out: `formats="jpeg|png",size=7x3`,
+	},
+	{
+		in:  T8{Formats: []string{"jpeg|png", "gif"}},
+		err: errors.New(`tuples: marshal failed: list delimiter '|' in list element "jpeg|png"`),
+	},
+	{
+		in:   map[string]any{"a": []int{1, 2}},
+		out:  "a=1/2",
+		opts: []tuples.Option{tuples.WithListDelimiter('/')},
+	},
+
+	// output nested tuples
+	{
+		in:  T9{Size: T9Size{H: 700, W: 350}, Crop: tuples.Some(T9Size{H: 1}), Sizes: []*T9Size{{H: 1, W: 2}, nil, {H: 3, W: 4}}, Format: "jpeg"},
+		out: "size=(h=700,w=350),crop=(h=1,w=0),sizes=[(h=1,w=2),(h=3,w=4)],f=jpeg",
+	},
+	{
+		in:  T9{Thumb: &T9Size{}, Sizes: []*T9Size{}},
+		out: `size=(h=0,w=0),thumb=(h=0,w=0),f=""`,
+	},
+	{
+		in:   map[string]any{"size": map[string]int{"h": 1}, "sizes": []any{map[string]int{"w": 2}}},
+		out:  "size;(h;1):sizes;[(w;2)]",
+		opts: []tuples.Option{tuples.WithFieldsDelimiter(':'), tuples.WithKeyValueDelimiter(';')},
+	},
+
+	// output a map keyed by the key field
+	{
+		in:  map[string]T10{"small": {Height: 100}, "large": {ID: "large", Height: 900}, "b": {ID: "x"}},
+		out: "h=0,id=b h=900,id=large h=100,id=small",
+	},
+	{
+		in:  &map[string]*T10{"a": {Height: 1}, "b": nil},
+		out: "h=1,id=a",
+	},
+	{
+		in:  map[int]T10{65: {Height: 1}},
+		out: "h=1,id=65",
+	},
+	{
+		in:  map[int8]T10Int{1: {Format: "png"}},
+		out: "id=1,f=png",
+	},
+	{
+		in:  map[string]T10Int{"1": {Format: "png"}},
+		out: "id=1,f=png",
+	},
+	{
+		in:  map[string]T10Int{"a": {Format: "png"}},
+		err: errors.New(`tuples: marshal failed: invalid key field type: "a" map key for int8 key field`),
+	},
+	{
+		in:  map[float64]T10Int{1: {Format: "png"}},
+		err: errors.New("tuples: marshal failed: invalid key field type: float64 map key for int8 key field"),
+	},
+
+	// output the kind of registered structs
+	{
+		in:   []TOp{TResize{W: 10}, &TCrop{X: 1}},
+		out:  "kind=resize,w=10,h=0 kind=crop,x=1,y=0",
+		opts: []tuples.Option{tuples.WithKind("resize", TResize{}), tuples.WithKind("crop", TCrop{})},
+	},
+	{
+		in:   TPipeline{Name: "a", Op: TResize{H: 5}},
+		out:  "name=a,op=(type=resize,w=0,h=5)",
+		opts: []tuples.Option{tuples.WithKind("resize", TResize{}), tuples.WithKindKey("type")},
+	},
+	{
+		in:   T11Kind{Kind: "own", N: 1},
+		out:  "kind=own,n=1",
+		opts: []tuples.Option{tuples.WithKind("k11", T11Kind{})},
+	},
+
+	// output columns
+	{
+		in:   T12Columns{H: []int{700, 900}, W: []*int{ptrTo(350), nil, nil, ptrTo(1)}, F: []tuples.Optional[int]{{}, tuples.Some(2)}},
+		out:  "h=700,w=350 h=900,f=2 w=1",
+		opts: []tuples.Option{tuples.WithColumns()},
+	},
+	{
+		in:  T12Columns{H: []int{700, 900}},
+		out: "h=700,h=900",
+	},
+
+	// output positional tuples
+	{
+		in:   []T13Positional{{H: 700, W: ptrTo(350), Tags: []string{"a", "b"}, Format: "jpeg"}, {H: 900, Sizes: []T9Size{{H: 1}}}},
+		out:  `700,350,[a,b],jpeg,, 900,,,"",,[(h=1,w=0)]`,
+		opts: []tuples.Option{tuples.WithPositional()},
+	},
+	{
+		in:   map[string]any{"0": 1, "1": map[string]int{"h": 2}, "2": []int{3, 4}},
+		out:  "1,(h=2),3|4",
+		opts: []tuples.Option{tuples.WithPositional(), tuples.WithListDelimiter('|')},
+	},
+	{
+		in:  T13Positional{H: 700, Format: "jpeg"},
+		out: "h=700,f=jpeg",
+	},
+	{
+		in:   T13PosCollision{A: "x", B: "y"},
+		err:  errors.New("tuples: marshal failed: fields share a position: A and B at 0"),
+		opts: []tuples.Option{tuples.WithPositional()},
+	},
+	{
+		in:  T13PosCollision{A: "x"},
+		out: "a=x",
+	},
+
+	// output tuples with header
+	{
+		in:   []*T13Positional{{H: 700, W: ptrTo(350), Format: "jpeg", Tags: []string{"a"}}, nil, {H: 900}},
+		out:  `@h,w,f 700,350,jpeg 900,,""`,
+		opts: []tuples.Option{tuples.WithHeader()},
+	},
+	{
+		in:   []map[string]any{{"h": 700, "f": "jpeg"}, {"w": 450, "tags": []string{"a", "b"}}},
+		out:  "@f,h,tags,w jpeg,700,, ,,a|b,450",
+		opts: []tuples.Option{tuples.WithHeader(), tuples.WithListDelimiter('|')},
+	},
+	{
+		in:   T9{Size: T9Size{H: 1, W: 2}, Format: "png"},
+		out:  "@size,thumb,crop,sizes,f (h=1,w=2),,,,png",
+		opts: []tuples.Option{tuples.WithHeader()},
+	},
+	{
+		in:   []T1{},
+		out:  "@foo,baaar",
+		opts: []tuples.Option{tuples.WithHeader()},
+	},
+
+	// output quoted values
+	{
+		in:  map[string]any{"note": `a "b", c=d`, "f": "(x)", "tags": []string{"a b", "c"}},
+		out: `f="(x)",note="a ""b"", c=d",tags="a b",tags=c`,
+	},
+	{
+		in:  T9{Format: `"x`},
+		out: `size=(h=0,w=0),f="""x"`,
+	},
+
+	// output with custom delimiters
+	{
+		in:   T1{Foo: "hey", Bar: 25},
+		out:  "foo:hey;baaar:25",
+		opts: []tuples.Option{tuples.WithFieldsDelimiter(';'), tuples.WithKeyValueDelimiter(':')},
+	},
+
 	// ignore channels
 	{
 		in:  make(chan int),
@@ -103,9 +381,35 @@ var marshalTests = []marshalTest{
 	},
 }
 
+func TestMarshalInvalidOptions(t *testing.T) {
+	_, err := tuples.Marshal(T1{}, tuples.WithFieldsDelimiter('='))
+	want := errors.New("tuples: invalid delimiters: fields and key-value delimiters are equal")
+	if !eqErrors(err, want) {
+		t.Errorf("unexpected Marshal() error: \ngot  %v\nwant %v", err, want)
+	}
+}
+
+func TestMarshalDecodedInterface(t *testing.T) {
+	for _, in := range []string{"l=[a,b]", "f=png,l=[[a],b]", "l=[(h=1),(h=2)] n=[\"a b\",\"(x)\"]"} {
+		var v any
+		if err := tuples.Unmarshal([]byte(in), &v); err != nil {
+			t.Fatalf("unexpected Unmarshal() error: %v", err)
+		}
+
+		out, err := tuples.Marshal(v)
+		if err != nil {
+			t.Fatalf("unexpected Marshal() error: %v", err)
+		}
+
+		if string(out) != in {
+			t.Errorf("Marshal() output:\ngot  %s\nwant %s", out, in)
+		}
+	}
+}
+
 func TestMarshal(t *testing.T) {
 	for tI, tC := range marshalTests {
-		got, err := tuples.Marshal(tC.in)
+		got, err := tuples.Marshal(tC.in, tC.opts...)
 		if err != nil {
 			var e *tuples.MarshalError
 			if !errors.As(err, &e) {
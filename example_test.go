@@ -3,6 +3,7 @@ package tuples_test
 import (
 	"fmt"
 	"io"
+	"os"
 	"strings"
 
 	"github.com/antklim/tuples"
@@ -124,3 +125,122 @@ func ExampleReader_ReadAll() {
 	// Output:
 	// [[John Doe 2000-01-01] [Bob Smith 2010-10-10]]
 }
+
+func ExampleReader_All() {
+	in := "fname=John,lname=Doe fname=Bob,lname=Smith"
+
+	r, err := tuples.NewReader(strings.NewReader(in))
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	for tuple, err := range r.All() {
+		if err != nil {
+			fmt.Println(err)
+		}
+		fmt.Printf("%v\n", tuple)
+	}
+
+	// Output:
+	// [John Doe]
+	// [Bob Smith]
+}
+
+func ExampleDecode() {
+	type format struct {
+		Height int    `tuples:"h"`
+		Format string `tuples:"f"`
+	}
+
+	in := "h=700,f=jpeg h=900,f=png"
+
+	for f, err := range tuples.Decode[format](strings.NewReader(in)) {
+		if err != nil {
+			fmt.Println(err)
+		}
+		fmt.Printf("%+v\n", f)
+	}
+
+	// Output:
+	// {Height:700 Format:jpeg}
+	// {Height:900 Format:png}
+}
+
+func ExampleParse() {
+	type format struct {
+		Height int    `tuples:"h"`
+		Format string `tuples:"f"`
+	}
+
+	formats, err := tuples.Parse[format]("h=700,f=jpeg h=900,f=png")
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Printf("%+v\n", formats)
+
+	// Output:
+	// [{Height:700 Format:jpeg} {Height:900 Format:png}]
+}
+
+func ExampleSelect() {
+	in := "h=700,f=jpeg h=900,f=png h=400,f=png h=1000,f=gif"
+
+	for t, err := range tuples.Select(strings.NewReader(in), "f in (jpeg,png),h>500") {
+		if err != nil {
+			fmt.Println(err)
+		}
+		fmt.Println(t)
+	}
+
+	// Output:
+	// h=700,f=jpeg
+	// h=900,f=png
+}
+
+func ExampleDiff() {
+	changes, err := tuples.Diff([]byte("h=700,f=jpeg h=900"), []byte("f=png,h=700 h=900 h=400"))
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(changes)
+
+	// Output:
+	// ~ tuple #1 h=700,f=jpeg
+	//   ~ f: jpeg -> png
+	// + tuple #3 h=400
+}
+
+func ExampleCanonicalize() {
+	c, err := tuples.Canonicalize([]byte(`w=350.0,h=0700  f="png"`), tuples.WithCanonicalNumbers())
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(string(c))
+
+	// Output:
+	// h=700,w=350 f=png
+}
+
+func ExampleWriter() {
+	w, err := tuples.NewWriter(os.Stdout)
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	if err := w.Write([]tuples.Field{{Key: "h", Value: "700"}, {Key: "note", Value: "a b"}}); err != nil {
+		fmt.Println(err)
+	}
+
+	if err := w.WriteMap(map[string]string{"f": "png"}); err != nil {
+		fmt.Println(err)
+	}
+
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// h=700,note="a b" f=png
+}
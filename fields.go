@@ -1,46 +1,132 @@
 package tuples
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 	"sync"
+	"unicode/utf8"
 )
 
 type field struct {
 	name string
 	tag  string
+	sep  rune // list values delimiter
+	key  bool // identity field of the struct, used to merge tuples
 }
 
 type typFields struct {
 	fields      []field
 	fieldsByTag map[string]int
+	fieldsByPos map[string]int // positions of positional tuple fields
+	posErr      error          // fields with the same position, positional tuples cannot be used
 }
 
 var fieldsCache sync.Map // map[reflect.Type]typFields
 
+var errFieldsPosition = errors.New("fields share a position")
+
 // typeFields returns a list of fields that should be recognized for the given
 // type.
 func typeFields(t reflect.Type) typFields {
 	var fields []field
+	var positions []int
 
 	for i := 0; i < t.NumField(); i++ {
 		fld := t.Field(i)
 
 		if tag := fld.Tag.Get("tuples"); tag != "" {
+			name, opts := parseTag(tag)
+
 			f := field{
 				name: fld.Name,
-				tag:  tag,
+				tag:  name,
+			}
+
+			if sep, ok := opts["sep"]; ok {
+				f.sep, _ = utf8.DecodeRuneInString(sep)
+			}
+
+			_, f.key = opts["key"]
+
+			pos := len(fields)
+			if p, err := strconv.Atoi(opts["pos"]); err == nil && p >= 0 {
+				pos = p
 			}
 
 			fields = append(fields, f)
+			positions = append(positions, pos)
 		}
 	}
 
 	fieldsByTag := make(map[string]int)
 	for i, f := range fields {
-		fieldsByTag[f.tag] = i
+		if f.tag != "" {
+			fieldsByTag[f.tag] = i
+		}
+	}
+
+	// An explicit position can collide with another one or with the implied
+	// position of a field, i.e. its declaration order. The first declared
+	// field keeps the position and the collision is reported on use.
+	var posErr error
+
+	fieldsByPos := make(map[string]int)
+	for i, pos := range positions {
+		if j, ok := fieldsByPos[strconv.Itoa(pos)]; ok {
+			if posErr == nil {
+				posErr = fmt.Errorf("%w: %s and %s at %d", errFieldsPosition, fields[j].name, fields[i].name, pos)
+			}
+
+			continue
+		}
+
+		fieldsByPos[strconv.Itoa(pos)] = i
+	}
+
+	return typFields{fields, fieldsByTag, fieldsByPos, posErr}
+}
+
+// lookup returns the field the scanned tuple field is decoded into. Fields of
+// a positional tuple are looked up by position, other fields by tag. See
+// posErr for positions of a struct that cannot be used.
+func (tf typFields) lookup(n node) (field, bool) {
+	idx, ok := tf.fieldsByTag[n.key]
+	if n.positional {
+		idx, ok = tf.fieldsByPos[n.key]
+	}
+
+	if !ok {
+		return field{}, false
+	}
+
+	return tf.fields[idx], true
+}
+
+// positions returns the number of positions of a positional tuple, i.e. the
+// highest field position plus one.
+func (tf typFields) positions() int {
+	n := 0
+	for key := range tf.fieldsByPos {
+		if p, _ := strconv.Atoi(key); p >= n {
+			n = p + 1
+		}
+	}
+
+	return n
+}
+
+// keyField returns the first field marked with the key tag option.
+func (tf typFields) keyField() (field, bool) {
+	for _, f := range tf.fields {
+		if f.key {
+			return f, true
+		}
 	}
 
-	return typFields{fields, fieldsByTag}
+	return field{}, false
 }
 
 // cachedTypeFields runs typeFields and stores the result in the cache.
@@ -53,3 +139,30 @@ func cachedTypeFields(t reflect.Type) typFields {
 
 	return cache.(typFields)
 }
+
+// tagOptions holds the options following a comma in the "tuples" tag, i.e.
+// "sep=|" in `tuples:"formats,sep=|"`. Options without value map to "".
+type tagOptions map[string]string
+
+// parseTag splits the "tuples" tag into the field name and its options.
+// The sep option value is a single rune, so it can be a comma itself.
+func parseTag(tag string) (string, tagOptions) {
+	name, rest, _ := strings.Cut(tag, ",")
+	opts := make(tagOptions)
+
+	for rest != "" {
+		var opt string
+
+		if strings.HasPrefix(rest, "sep=") && len(rest) > len("sep=") {
+			_, size := utf8.DecodeRuneInString(rest[len("sep="):])
+			opt, rest = rest[:len("sep=")+size], strings.TrimPrefix(rest[len("sep=")+size:], ",")
+		} else {
+			opt, rest, _ = strings.Cut(rest, ",")
+		}
+
+		k, v, _ := strings.Cut(opt, "=")
+		opts[k] = v
+	}
+
+	return name, opts
+}
@@ -1,29 +1,42 @@
 package tuples
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 )
 
 func TestCachedTypeFields(t *testing.T) {
 	var out struct {
-		Name        string `tuples:"fname"`
+		Name        string `tuples:"fname,key"`
 		Surname     string `tuples:"lname"`
 		DateOfBirth string
-		Age         int `tuples:"age"`
+		Age         int      `tuples:"age"`
+		Kids        []string `tuples:"kids,sep=|"`
+		Note        string   `tuples:",pos=1"`
 	}
 
 	expected := typFields{
 		fields: []field{
-			{name: "Name", tag: "fname"},
+			{name: "Name", tag: "fname", key: true},
 			{name: "Surname", tag: "lname"},
 			{name: "Age", tag: "age"},
+			{name: "Kids", tag: "kids", sep: '|'},
+			{name: "Note"},
 		},
 		fieldsByTag: map[string]int{
 			"fname": 0,
 			"lname": 1,
 			"age":   2,
+			"kids":  3,
 		},
+		fieldsByPos: map[string]int{
+			"0": 0,
+			"1": 1,
+			"2": 2,
+			"3": 3,
+		},
+		posErr: fmt.Errorf("%w: Surname and Note at 1", errFieldsPosition),
 	}
 
 	got := cachedTypeFields(reflect.TypeOf(out))
@@ -31,3 +44,24 @@ func TestCachedTypeFields(t *testing.T) {
 		t.Errorf("typeFields() output:\ngot  %v\nwant %v", got, expected)
 	}
 }
+
+func TestParseTag(t *testing.T) {
+	testCases := []struct {
+		tag  string
+		name string
+		opts tagOptions
+	}{
+		{tag: "formats", name: "formats", opts: tagOptions{}},
+		{tag: "formats,sep=|", name: "formats", opts: tagOptions{"sep": "|"}},
+		{tag: "formats,sep=,", name: "formats", opts: tagOptions{"sep": ","}},
+		{tag: "formats,sep=,,key", name: "formats", opts: tagOptions{"sep": ",", "key": ""}},
+		{tag: ",sep=;,key", name: "", opts: tagOptions{"sep": ";", "key": ""}},
+	}
+
+	for tI, tC := range testCases {
+		name, opts := parseTag(tC.tag)
+		if name != tC.name || !reflect.DeepEqual(opts, tC.opts) {
+			t.Errorf("#%d: parseTag(%q) output:\ngot  %q %v\nwant %q %v", tI, tC.tag, name, opts, tC.name, tC.opts)
+		}
+	}
+}
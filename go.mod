@@ -1,3 +1,3 @@
 module github.com/antklim/tuples
 
-go 1.18
+go 1.23
@@ -0,0 +1,193 @@
+package tuples
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const headerMark = '@'
+
+var errInvalidHeader = errors.New("invalid header")
+
+// parseHeader parses the header tuple, i.e. "@h,w,f", into the declared keys.
+// Keys must be unique and must not contain key-value delimiter or brackets.
+func parseHeader(s string, opts scannerOptions) ([]string, error) {
+	if !strings.HasPrefix(s, string(headerMark)) {
+		return nil, errInvalidHeader
+	}
+
+	keys := strings.Split(s[len(string(headerMark)):], string(opts.fd))
+	seen := make(map[string]bool, len(keys))
+
+	for _, key := range keys {
+		if key == "" || seen[key] || strings.ContainsRune(key, opts.kvd) || strings.IndexFunc(key, isBracket) >= 0 {
+			return nil, errInvalidHeader
+		}
+
+		seen[key] = true
+	}
+
+	return keys, nil
+}
+
+// keyByHeader sets the keys of the positional fields by the header. It returns
+// the number of the first field without a header key along with the error.
+func keyByHeader(flds []node, header []string) (int, error) {
+	for i := range flds {
+		if i >= len(header) {
+			return i + 1, errInvalidField
+		}
+
+		flds[i].key, flds[i].positional = header[i], false
+	}
+
+	return 0, nil
+}
+
+// headerTuples writes v as a header tuple followed by tuples of values in the
+// header keys order, i.e. "@h,w,f 700,350,jpeg". v is a struct, a map or
+// a list of them.
+func (e *encoder) headerTuples(v reflect.Value) error {
+	v = unwrapElement(v)
+
+	var elems []reflect.Value
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if elem := unwrapElement(v.Index(i)); elem.IsValid() {
+				elems = append(elems, elem)
+			}
+		}
+	case reflect.Struct, reflect.Map:
+		elems = append(elems, v)
+	default:
+		return e.encode(v)
+	}
+
+	keys := headerKeys(v.Type(), elems)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if err := e.writeHeader(keys); err != nil {
+		return err
+	}
+
+	for _, elem := range elems {
+		if _, err := e.b.WriteRune(tuplesDelimiter); err != nil {
+			return &MarshalError{err}
+		}
+
+		if err := e.headerValues(elem, keys); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// headerKeys returns the keys of the elements in the order of their first
+// appearance. Struct keys are in the fields order, map keys are sorted. Keys
+// of an empty list of structs are taken from the struct type.
+func headerKeys(t reflect.Type, elems []reflect.Value) []string {
+	var keys []string
+
+	seen := make(map[string]bool)
+	add := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	addStruct := func(t reflect.Type) {
+		for _, f := range cachedTypeFields(t).fields {
+			if f.tag != "" {
+				add(f.tag)
+			}
+		}
+	}
+
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && indirectType(t.Elem()).Kind() == reflect.Struct {
+		addStruct(indirectType(t.Elem()))
+	}
+
+	for _, elem := range elems {
+		switch elem.Kind() {
+		case reflect.Struct:
+			addStruct(elem.Type())
+		case reflect.Map:
+			mkeys := make([]string, 0, elem.Len())
+			for _, mapKey := range elem.MapKeys() {
+				mkeys = append(mkeys, fmt.Sprint(mapKey.Interface()))
+			}
+
+			sort.Strings(mkeys)
+
+			for _, key := range mkeys {
+				add(key)
+			}
+		}
+	}
+
+	return keys
+}
+
+func (e *encoder) writeHeader(keys []string) error {
+	if _, err := e.b.WriteRune(headerMark); err != nil {
+		return &MarshalError{err}
+	}
+
+	if _, err := e.b.WriteString(strings.Join(keys, string(e.opts.fieldsDelimiter))); err != nil {
+		return &MarshalError{err}
+	}
+
+	return nil
+}
+
+// headerValues writes values of the struct or the map v in the keys order.
+// Missing and omitted values are written as empty fields.
+func (e *encoder) headerValues(v reflect.Value, keys []string) error {
+	vals := make(map[string]reflect.Value, len(keys))
+	seps := make(map[string]rune, len(keys))
+
+	if v.Kind() == reflect.Struct {
+		for _, f := range cachedTypeFields(v.Type()).fields {
+			if f.tag == "" {
+				continue
+			}
+
+			sep, err := e.opts.fieldListDelimiter(f)
+			if err != nil {
+				return err
+			}
+
+			vals[f.tag], seps[f.tag] = v.FieldByName(f.name), sep
+		}
+	} else {
+		for _, mapKey := range v.MapKeys() {
+			key := fmt.Sprint(mapKey.Interface())
+			vals[key], seps[key] = v.MapIndex(mapKey), e.opts.listDelimiter
+		}
+	}
+
+	for i, key := range keys {
+		if i > 0 {
+			if _, err := e.b.WriteRune(e.opts.fieldsDelimiter); err != nil {
+				return &MarshalError{err}
+			}
+		}
+
+		if val, ok := vals[key]; ok {
+			if err := e.positionalValue(val, seps[key]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package tuples
+
+import (
+	"io"
+	"iter"
+)
+
+// All returns an iterator over the tuples values. Every tuple is returned the
+// same way as by Read. The iteration stops after the first error.
+//
+// Usage:
+//
+//	for tuple, err := range r.All() {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(tuple)
+//	}
+func (r *Reader) All() iter.Seq2[[]string, error] {
+	return func(yield func([]string, error) bool) {
+		for {
+			tuple, err := r.readTuple()
+			if err == io.EOF || !yield(tuple, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Fields returns an iterator over the tuples fields, i.e. key-value pairs in
+// the order they appear in the tuple. The iteration stops after the first
+// error.
+func (r *Reader) Fields() iter.Seq2[[]Field, error] {
+	return func(yield func([]Field, error) bool) {
+		for {
+			flds, err := r.readFields()
+			if err == io.EOF || !yield(flds, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Decode returns an iterator over the tuples read from r and decoded into
+// values of type T, see Reader.Decode. The iteration stops after the first
+// error.
+//
+// Usage:
+//
+//	for f, err := range tuples.Decode[Format](r) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(f)
+//	}
+func Decode[T any](r io.Reader, opts ...Option) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		tr, err := NewReader(r, opts...)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		for {
+			var v T
+
+			err := tr.Decode(&v)
+			if err == io.EOF || !yield(v, err) || err != nil {
+				return
+			}
+		}
+	}
+}
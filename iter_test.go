@@ -0,0 +1,160 @@
+package tuples_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/antklim/tuples"
+)
+
+func TestReaderAll(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		in    string
+		limit int // stop iteration after limit tuples, 0 to read all
+		out   [][]string
+		err   error
+	}{
+		{
+			desc: "All tuples",
+			in:   "h=700,w=350 h=900,w=450",
+			out:  [][]string{{"700", "350"}, {"900", "450"}},
+		},
+		{
+			desc:  "Break",
+			in:    "h=700,w=350 h=900,w=450 h=1",
+			limit: 1,
+			out:   [][]string{{"700", "350"}},
+		},
+		{
+			desc: "Stops on error",
+			in:   "h=700 h h=1",
+			out:  [][]string{{"700"}},
+			err:  errors.New("tuples: scan failed: tuple #2 invalid field #1"),
+		},
+	}
+
+	for tI, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			r, err := tuples.NewReader(strings.NewReader(tC.in))
+			if err != nil {
+				t.Fatalf("#%d: unexpected NewReader() error: %v", tI, err)
+			}
+
+			var out [][]string
+			var rerr error
+			for tuple, err := range r.All() {
+				if err != nil {
+					rerr = err
+					continue
+				}
+
+				out = append(out, tuple)
+				if len(out) == tC.limit {
+					break
+				}
+			}
+
+			if !eqErrors(rerr, tC.err) {
+				t.Errorf("#%d: All() error mismatch:\ngot  %v\nwant %v", tI, rerr, tC.err)
+			}
+
+			if !reflect.DeepEqual(out, tC.out) {
+				t.Errorf("#%d: All() output:\ngot  %v\nwant %v", tI, out, tC.out)
+			}
+		})
+	}
+}
+
+func TestReaderFields(t *testing.T) {
+	r, err := tuples.NewReader(strings.NewReader("ro,uid=0 @1,f=png"), tuples.WithBareKeys())
+	if err != nil {
+		t.Fatalf("unexpected NewReader() error: %v", err)
+	}
+
+	var out [][]tuples.Field
+	for flds, err := range r.Fields() {
+		if err != nil {
+			t.Fatalf("unexpected Fields() error: %v", err)
+		}
+
+		out = append(out, flds)
+	}
+
+	expected := [][]tuples.Field{
+		{{Key: "ro"}, {Key: "uid", Value: "0"}},
+		{{Key: "@1"}, {Key: "f", Value: "png"}},
+	}
+
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("Fields() output:\ngot  %v\nwant %v", out, expected)
+	}
+}
+
+func TestDecodeIter(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		in    string
+		opts  []tuples.Option
+		limit int // stop iteration after limit values, 0 to read all
+		out   []T
+		err   error
+	}{
+		{
+			desc: "All tuples",
+			in:   "name=John,age=23 name=Bob",
+			out:  []T{{Name: "John", Age: 23}, {Name: "Bob"}},
+		},
+		{
+			desc:  "Break",
+			in:    "name=John name=Bob",
+			limit: 1,
+			out:   []T{{Name: "John"}},
+		},
+		{
+			desc: "Header",
+			in:   "@name,adult John,true",
+			opts: []tuples.Option{tuples.WithHeader()},
+			out:  []T{{Name: "John", IsAdult: true}},
+		},
+		{
+			desc: "Stops on error",
+			in:   "name=John age=x name=Bob",
+			out:  []T{{Name: "John"}},
+			err:  &tuples.UnmarshalError{Value: "x", Type: reflect.TypeOf(1)},
+		},
+		{
+			desc: "Invalid options",
+			opts: []tuples.Option{tuples.WithFieldsDelimiter('=')},
+			err:  errors.New("tuples: invalid delimiters: fields and key-value delimiters are equal"),
+		},
+	}
+
+	for tI, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			var out []T
+			var derr error
+			for v, err := range tuples.Decode[T](strings.NewReader(tC.in), tC.opts...) {
+				if err != nil {
+					derr = err
+					continue
+				}
+
+				out = append(out, v)
+				if len(out) == tC.limit {
+					break
+				}
+			}
+
+			if !eqErrors(derr, tC.err) {
+				t.Errorf("#%d: Decode() error mismatch:\ngot  %v\nwant %v", tI, derr, tC.err)
+			}
+
+			if !reflect.DeepEqual(out, tC.out) {
+				t.Errorf("#%d: Decode() output:\ngot  %v\nwant %v", tI, out, tC.out)
+			}
+		})
+	}
+}
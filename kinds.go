@@ -0,0 +1,100 @@
+package tuples
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// defaultKindKey is the default key of a tuple field that holds the kind of
+// the tuple, i.e. "kind" in "kind=resize,w=10".
+const defaultKindKey = "kind"
+
+var errUnknownKind = errors.New("unknown tuple kind")
+
+var (
+	kindsMu   sync.RWMutex
+	kindTypes = map[string]reflect.Type{}
+	kindNames = map[reflect.Type]string{}
+)
+
+// RegisterKind records the struct type of v under the kind name. Unmarshal
+// uses it to decode a tuple with the kind field, i.e. "kind=resize,w=10",
+// into an interface value, i.e. an element of []Op, where Op is implemented
+// by the struct or a pointer to it. Marshal writes the kind field of
+// a registered struct automatically.
+//
+// RegisterKind panics if v is not a struct or a pointer to a struct, or if
+// the name or the type is already registered for a different type or name.
+func RegisterKind(name string, v any) {
+	t := kindType(v)
+
+	kindsMu.Lock()
+	defer kindsMu.Unlock()
+
+	// Both names are checked before storing, so a panic registers nothing.
+	if prev, ok := kindTypes[name]; ok && prev != t {
+		panic(fmt.Sprintf("tuples: registering duplicate types for kind %q: %s != %s", name, prev, t))
+	}
+
+	if prev, ok := kindNames[t]; ok && prev != name {
+		panic(fmt.Sprintf("tuples: registering duplicate kinds for %s: %q != %q", t, prev, name))
+	}
+
+	kindTypes[name] = t
+	kindNames[t] = name
+}
+
+// kindType returns the struct type of v or of the struct v points to.
+func kindType(v any) reflect.Type {
+	t := reflect.TypeOf(v)
+	if t == nil || indirectType(t).Kind() != reflect.Struct {
+		panic(fmt.Sprintf("tuples: kind must be a struct, got %v", t))
+	}
+
+	return indirectType(t)
+}
+
+// kind returns the struct type registered with the option or globally under
+// the name.
+func (o *options) kind(name string) (reflect.Type, bool) {
+	if t, ok := o.kinds[name]; ok {
+		return t, true
+	}
+
+	kindsMu.RLock()
+	defer kindsMu.RUnlock()
+
+	t, ok := kindTypes[name]
+
+	return t, ok
+}
+
+// kindName returns the kind name registered with the option or globally for
+// the struct type t.
+func (o *options) kindName(t reflect.Type) (string, bool) {
+	for name, kt := range o.kinds {
+		if kt == t {
+			return name, true
+		}
+	}
+
+	kindsMu.RLock()
+	defer kindsMu.RUnlock()
+
+	name, ok := kindNames[t]
+
+	return name, ok
+}
+
+// tupleKind returns the value of the kind field of the tuple.
+func tupleKind(flds []node, kindKey string) (string, bool) {
+	for _, fld := range flds {
+		if fld.key == kindKey && fld.kind == nodeValue {
+			return fld.value, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,63 @@
+package tuples_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/antklim/tuples"
+)
+
+type kindA struct {
+	A int `tuples:"a"`
+}
+
+type kindB struct {
+	B int `tuples:"b"`
+}
+
+func TestRegisterKind(t *testing.T) {
+	tuples.RegisterKind("kind-a", kindA{})
+	tuples.RegisterKind("kind-a", &kindA{}) // the same type registered again
+
+	testCases := []struct {
+		desc string
+		name string
+		v    any
+	}{
+		{desc: "Panics on duplicate kind name", name: "kind-a", v: kindB{}},
+		{desc: "Panics on duplicate kind type", name: "kind-aa", v: kindA{}},
+		{desc: "Panics on non-struct kind", name: "kind-int", v: 1},
+		{desc: "Panics on nil kind", name: "kind-nil", v: nil},
+	}
+
+	for tI, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("#%d: RegisterKind() should panic", tI)
+				}
+			}()
+
+			tuples.RegisterKind(tC.name, tC.v)
+		})
+	}
+	// Failed registrations leave no kinds behind.
+	var out []any
+	if err := tuples.Unmarshal([]byte("kind=kind-a,a=1 kind=kind-aa,a=2"), &out); err != nil {
+		t.Fatalf("unexpected Unmarshal() error: %v", err)
+	}
+
+	expected := []any{kindA{A: 1}, map[string]any{"kind": "kind-aa", "a": "2"}}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("Unmarshal() output:\ngot  %v\nwant %v", out, expected)
+	}
+
+	b, err := tuples.Marshal(kindA{A: 1})
+	if err != nil {
+		t.Fatalf("unexpected Marshal() error: %v", err)
+	}
+
+	if want := "kind=kind-a,a=1"; string(b) != want {
+		t.Errorf("Marshal() output:\ngot  %s\nwant %s", b, want)
+	}
+}
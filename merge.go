@@ -0,0 +1,112 @@
+package tuples
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	// mergeDelete marks an overlay key to delete, i.e. "-h".
+	mergeDelete = "-"
+	// mergeAppend marks an overlay key to append, i.e. "+tag=b".
+	mergeAppend = "+"
+)
+
+// Merge returns a new document with the overlays applied to the base in order.
+// Neither the base nor the overlays are changed. The result has the options of
+// the base.
+//
+// Tuples are matched by the value of the identity key set with the
+// WithMergeKey option of the base, or by their position without it. The fields
+// of a matched overlay tuple change the base tuple:
+//
+//	h=900     sets the key, the base field keeps its position
+//	+tag=b    appends one more field with the key, the base fields are kept
+//	-h        deletes the key
+//	-id=db    deletes the whole tuple, when id is the identity key
+//
+// Overlay tuples that match no base tuple are appended. The result keeps the
+// tuples and keys order of the base.
+//
+// Usage:
+//
+//	defaults, err := tuples.ParseDocument(defaultsData, tuples.WithMergeKey("name"), tuples.WithBareKeys())
+//	env, err := tuples.ParseDocument(envData, tuples.WithBareKeys())
+//	var cfg []Service
+//	err = tuples.Merge(defaults, env).Decode(&cfg)
+func Merge(base *Document, overlays ...*Document) *Document {
+	key := base.opts.mergeKey
+
+	merged := make([]Tuple, 0, len(base.tuples))
+	for _, t := range base.tuples {
+		merged = append(merged, slices.Clone(t))
+	}
+
+	for _, overlay := range overlays {
+		for pos, ot := range overlay.tuples {
+			matched := false
+
+			for i := range merged {
+				if merged[i] != nil && sameIdentity(merged[i], ot, key, i, pos) {
+					merged[i] = mergeTuple(merged[i], ot, key)
+					matched = true
+				}
+			}
+
+			if !matched {
+				if t := mergeTuple(Tuple{}, ot, key); len(t) > 0 {
+					merged = append(merged, t)
+				}
+			}
+		}
+	}
+
+	doc := &Document{tuples: make([]Tuple, 0, len(merged)), opts: base.opts}
+	for _, t := range merged {
+		if t != nil {
+			doc.tuples = append(doc.tuples, t)
+		}
+	}
+
+	return doc
+}
+
+// sameIdentity reports whether the overlay tuple ot at the position pos
+// matches the tuple t at the position i. The identity of a tuple to delete is
+// the value of the delete marked key, i.e. "-id=db".
+func sameIdentity(t, ot Tuple, key string, i, pos int) bool {
+	if key == "" {
+		return i == pos
+	}
+
+	id, ok := t.Lookup(key)
+	if !ok {
+		return false
+	}
+
+	oid, ok := ot.Lookup(key)
+	if !ok {
+		oid, ok = ot.Lookup(mergeDelete + key)
+	}
+
+	return ok && id == oid
+}
+
+// mergeTuple applies the fields of the overlay tuple ot to the tuple t. It
+// returns nil when the tuple is deleted.
+func mergeTuple(t, ot Tuple, key string) Tuple {
+	for _, f := range ot {
+		switch {
+		case key != "" && f.Key == mergeDelete+key:
+			return nil
+		case strings.HasPrefix(f.Key, mergeDelete):
+			t.Delete(strings.TrimPrefix(f.Key, mergeDelete))
+		case strings.HasPrefix(f.Key, mergeAppend):
+			t = append(t, Field{Key: strings.TrimPrefix(f.Key, mergeAppend), Value: f.Value, Quoted: f.Quoted})
+		default:
+			t.SetField(f)
+		}
+	}
+
+	return t
+}
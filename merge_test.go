@@ -0,0 +1,118 @@
+package tuples_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/antklim/tuples"
+)
+
+func TestMerge(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		base     string
+		overlays []string
+		opts     []tuples.Option
+		out      string
+	}{
+		{
+			desc:     "by position",
+			base:     "h=700,w=350,f=jpeg",
+			overlays: []string{"f=png,q=80", "-w,h=900"},
+			out:      "h=900,f=png,q=80",
+		},
+		{
+			desc:     "by position with extra tuples",
+			base:     "h=700",
+			overlays: []string{"h=800 h=900"},
+			out:      "h=800 h=900",
+		},
+		{
+			desc:     "by identity key",
+			base:     "name=db,host=localhost,port=5432 name=cache,host=localhost",
+			overlays: []string{"name=cache,host=redis name=queue,host=mq", "port=6543,name=db"},
+			opts:     []tuples.Option{tuples.WithMergeKey("name")},
+			out:      "name=db,host=localhost,port=6543 name=cache,host=redis name=queue,host=mq",
+		},
+		{
+			desc:     "delete tuple",
+			base:     "name=db,port=5432 name=cache",
+			overlays: []string{"-name=db name=new,-name=new"},
+			opts:     []tuples.Option{tuples.WithMergeKey("name")},
+			out:      "name=cache",
+		},
+		{
+			desc:     "append and delete keys",
+			base:     "name=db,tag=a,debug,port=5432",
+			overlays: []string{"name=db,+tag=b,-debug,-missing name=new,+tag=c,-x"},
+			opts:     []tuples.Option{tuples.WithMergeKey("name"), tuples.WithBareKeys()},
+			out:      "name=db,tag=a,port=5432,tag=b name=new,tag=c",
+		},
+		{
+			desc:     "base without identity key",
+			base:     "host=localhost",
+			overlays: []string{"host=remote"},
+			opts:     []tuples.Option{tuples.WithMergeKey("name")},
+			out:      "host=localhost host=remote",
+		},
+		{
+			desc: "no overlays",
+			base: "h=700,w=350",
+			out:  "h=700,w=350",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			base := parseDocument(t, tC.base, tC.opts...)
+			before := base.String()
+
+			var overlays []*tuples.Document
+			for _, o := range tC.overlays {
+				overlays = append(overlays, parseDocument(t, o, tuples.WithBareKeys()))
+			}
+
+			merged := tuples.Merge(base, overlays...)
+			if out := merged.String(); out != tC.out {
+				t.Errorf("Merge() output:\ngot  %s\nwant %s", out, tC.out)
+			}
+
+			if after := base.String(); after != before {
+				t.Errorf("Merge() changed the base:\ngot  %s\nwant %s", after, before)
+			}
+		})
+	}
+}
+
+func TestMergeDecode(t *testing.T) {
+	type service struct {
+		Name string   `tuples:"name"`
+		Host string   `tuples:"host"`
+		Port int      `tuples:"port"`
+		Tags []string `tuples:"tag"`
+	}
+
+	base := parseDocument(t, "name=db,host=localhost,port=5432,tag=a name=cache,host=localhost",
+		tuples.WithMergeKey("name"), tuples.WithDuplicateKeys(tuples.DuplicateKeysCollect))
+	env := parseDocument(t, "name=db,+tag=b,port=6543 -name=cache")
+
+	var services []service
+	if err := tuples.Merge(base, env).Decode(&services); err != nil {
+		t.Fatalf("unexpected Decode() error: %v", err)
+	}
+
+	expected := []service{{Name: "db", Host: "localhost", Port: 6543, Tags: []string{"a", "b"}}}
+	if !reflect.DeepEqual(services, expected) {
+		t.Errorf("Decode() output:\ngot  %+v\nwant %+v", services, expected)
+	}
+}
+
+func parseDocument(t *testing.T, s string, opts ...tuples.Option) *tuples.Document {
+	t.Helper()
+
+	doc, err := tuples.ParseDocument([]byte(s), opts...)
+	if err != nil {
+		t.Fatalf("unexpected ParseDocument(%q) error: %v", s, err)
+	}
+
+	return doc
+}
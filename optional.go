@@ -0,0 +1,71 @@
+package tuples
+
+import "reflect"
+
+// Optional holds a value that may or may not be set. Unmarshal sets it only
+// when the tuple contains the field key, and Marshal omits unset values.
+type Optional[T any] struct {
+	value T
+	valid bool
+}
+
+// Some returns an Optional with the value v set.
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{value: v, valid: true}
+}
+
+// Get returns the stored value and whether it was set.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.valid
+}
+
+// Set stores the value v and marks the Optional as set.
+func (o *Optional[T]) Set(v T) {
+	o.value = v
+	o.valid = true
+}
+
+// Reset clears the stored value.
+func (o *Optional[T]) Reset() {
+	var zero T
+	o.value = zero
+	o.valid = false
+}
+
+func (o Optional[T]) optionalValue() (any, bool) {
+	return o.value, o.valid
+}
+
+func (Optional[T]) optionalType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (o *Optional[T]) setOptional(v reflect.Value) {
+	o.Set(v.Interface().(T))
+}
+
+// optional is implemented by Optional of any type. It lets the decoder and
+// encoder reach the wrapped value without knowing the type parameter.
+type optional interface {
+	optionalValue() (any, bool)
+	optionalType() reflect.Type
+}
+
+// optionalSetter is implemented by a pointer to Optional of any type.
+type optionalSetter interface {
+	optional
+	setOptional(v reflect.Value)
+}
+
+var optionalSetterType = reflect.TypeOf((*optionalSetter)(nil)).Elem()
+
+// asOptional returns v as an optional when v is an Optional of any type.
+func asOptional(v reflect.Value) (optional, bool) {
+	if !v.IsValid() || v.Kind() != reflect.Struct || !v.CanInterface() {
+		return nil, false
+	}
+
+	o, ok := v.Interface().(optional)
+
+	return o, ok
+}
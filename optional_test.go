@@ -0,0 +1,28 @@
+package tuples_test
+
+import (
+	"testing"
+
+	"github.com/antklim/tuples"
+)
+
+func TestOptional(t *testing.T) {
+	var o tuples.Optional[int]
+	if v, ok := o.Get(); ok || v != 0 {
+		t.Errorf("zero Optional Get() output:\ngot  %v, %t\nwant 0, false", v, ok)
+	}
+
+	o.Set(0)
+	if v, ok := o.Get(); !ok || v != 0 {
+		t.Errorf("Optional Get() after Set(0) output:\ngot  %v, %t\nwant 0, true", v, ok)
+	}
+
+	o.Reset()
+	if v, ok := o.Get(); ok || v != 0 {
+		t.Errorf("Optional Get() after Reset() output:\ngot  %v, %t\nwant 0, false", v, ok)
+	}
+
+	if v, ok := tuples.Some("a").Get(); !ok || v != "a" {
+		t.Errorf("Some() Get() output:\ngot  %v, %t\nwant a, true", v, ok)
+	}
+}
@@ -0,0 +1,247 @@
+package tuples
+
+import "reflect"
+
+// Option describes an option of reading, decoding and encoding tuples,
+// i.e fields delimiter, key-value delimiter, etc.
+type Option func(*options)
+
+// ReaderOption describes a reader option. It is the same as Option, so any
+// option can be passed to the reader.
+type ReaderOption = Option
+
+type options struct {
+	fieldsDelimiter rune
+	keyValDelimiter rune
+	listDelimiter   rune
+	bareKeys        bool
+	duplicateKeys   DuplicateKeys
+	decodeMode      DecodeMode
+	kindKey         string
+	kinds           map[string]reflect.Type
+	missing         Missing
+	columns         bool
+	positional      bool
+	header          bool
+	schema          Schema
+	mergeKey        string
+	ignoreOrder     bool
+	numbers         bool
+}
+
+var defaultOptions = options{
+	fieldsDelimiter: fieldsDelimiter,
+	keyValDelimiter: keyValDelimiter,
+	kindKey:         defaultKindKey,
+}
+
+func newOptions(opts []Option) options {
+	o := defaultOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+func (o *options) scannerOptions() []scannerOption {
+	sopts := []scannerOption{
+		withFieldsDelimiter(o.fieldsDelimiter),
+		withKeyValueDelimiter(o.keyValDelimiter),
+	}
+
+	if o.listDelimiter != 0 {
+		sopts = append(sopts, withListDelimiter(o.listDelimiter))
+	}
+
+	if o.bareKeys {
+		sopts = append(sopts, withBareKeys())
+	}
+
+	if o.positional {
+		sopts = append(sopts, withPositional())
+	}
+
+	if o.header {
+		sopts = append(sopts, withHeader())
+	}
+
+	return sopts
+}
+
+func (o *options) scannerOpts() scannerOptions {
+	so := defaultScannerOptions
+	for _, opt := range o.scannerOptions() {
+		opt(&so)
+	}
+
+	return so
+}
+
+// validate checks delimiters the same way the scanner does.
+func (o *options) validate() error {
+	so := o.scannerOpts()
+	if err := so.validate(); err != nil {
+		return &InvalidScannerOptionError{err}
+	}
+
+	return nil
+}
+
+// fieldListDelimiter returns the list values delimiter of the struct field. The
+// field sep tag option overrides the list delimiter option. It returns 0 when
+// list values should not be split.
+func (o *options) fieldListDelimiter(f field) (rune, error) {
+	if f.sep == 0 {
+		return o.listDelimiter, nil
+	}
+
+	so := o.scannerOpts()
+	if err := so.validateListDelimiter(f.sep); err != nil {
+		return 0, &InvalidScannerOptionError{err}
+	}
+
+	return f.sep, nil
+}
+
+// WithFieldsDelimiter sets a custom fields delimiter option.
+// Default delimiter is ','.
+func WithFieldsDelimiter(d rune) Option {
+	return func(o *options) { o.fieldsDelimiter = d }
+}
+
+// WithKeyValueDelimiter sets a custom key-value delimiter option.
+// Default delimiter is '='.
+func WithKeyValueDelimiter(d rune) Option {
+	return func(o *options) { o.keyValDelimiter = d }
+}
+
+// WithListDelimiter sets a delimiter of list values packed into one field
+// value, i.e. '|' in "formats=jpeg|png|webp". Such values decoded into slice
+// and array fields. The delimiter can be set per field with the sep tag
+// option, i.e. `tuples:"formats,sep=|"`. By default lists are not split.
+func WithListDelimiter(d rune) Option {
+	return func(o *options) { o.listDelimiter = d }
+}
+
+// WithBareKeys allows fields without the key-value delimiter, e.g. "ro" in
+// "ro,noexec,uid=1000". Reader returns an empty value for a bare key and
+// Unmarshal decodes it to true for bool fields. Marshal writes true bool
+// values as bare keys and omits false ones.
+func WithBareKeys() Option {
+	return func(o *options) { o.bareKeys = true }
+}
+
+// WithDuplicateKeys sets the policy of handling repeated keys of a tuple on
+// decoding and reading maps. Default policy is DuplicateKeysLastWins.
+func WithDuplicateKeys(p DuplicateKeys) Option {
+	return func(o *options) { o.duplicateKeys = p }
+}
+
+// WithKind records the struct type of v under the kind name for one call, the
+// same way as RegisterKind does globally. Kinds recorded with the option take
+// precedence over the registered ones.
+func WithKind(name string, v any) Option {
+	t := kindType(v)
+
+	return func(o *options) {
+		kinds := make(map[string]reflect.Type, len(o.kinds)+1)
+		for k, kt := range o.kinds {
+			kinds[k] = kt
+		}
+
+		kinds[name] = t
+		o.kinds = kinds
+	}
+}
+
+// WithKindKey sets the key of a tuple field that holds the tuple kind.
+// Default key is "kind".
+func WithKindKey(key string) Option {
+	return func(o *options) { o.kindKey = key }
+}
+
+// WithPositional makes tuples positional, i.e. fields are values without
+// keys, as in "700,350,jpeg 900,450,png". Reader returns the values and reads
+// maps keyed by the field positions. Unmarshal maps values to struct fields
+// by the pos tag option, i.e. `tuples:",pos=0"`, or by the order of tagged
+// fields. Marshal writes values only, in the same order.
+func WithPositional() Option {
+	return func(o *options) { o.positional = true }
+}
+
+// WithHeader makes tuples declare their keys once, by the header tuple that
+// starts with '@', as in "@h,w,f 700,350,jpeg 900,450,png". The following
+// tuples are values in the header keys order. Reader returns the values and
+// reads maps keyed by the header keys, see Reader.Header. Unmarshal decodes
+// the values as if every tuple had the keys. Marshal writes the header first
+// and values only after it.
+func WithHeader() Option {
+	return func(o *options) { o.header = true }
+}
+
+// WithSchema sets how Reader checks keys of the tuples against the keys of the
+// first tuple, see Schema. A tuple that violates the schema causes
+// SchemaError.
+func WithSchema(s Schema) Option {
+	return func(o *options) { o.schema = s }
+}
+
+// WithMergeKey sets the identity key Merge matches tuples of the base and the
+// overlays by, i.e. "name" in "name=db,port=5432". By default tuples are
+// matched by their position.
+func WithMergeKey(key string) Option {
+	return func(o *options) { o.mergeKey = key }
+}
+
+// WithIgnoreTupleOrder makes Equal and Diff compare tuples regardless of
+// their order, i.e. "h=700 h=900" equals "h=900 h=700".
+func WithIgnoreTupleOrder() Option {
+	return func(o *options) { o.ignoreOrder = true }
+}
+
+// WithCanonicalNumbers makes Canonicalize and Fingerprint write decimal
+// numbers in the shortest form, i.e. "700" for "0700.0" and "1000" for "1e3".
+// By default values are kept as is, since "1.10" and "0755" can be versions
+// or file modes rather than numbers.
+func WithCanonicalNumbers() Option {
+	return func(o *options) { o.numbers = true }
+}
+
+// WithMissing sets how a column gets a value for a tuple that lacks the column
+// key on columnar decoding. Default is MissingZero.
+func WithMissing(m Missing) Option {
+	return func(o *options) { o.missing = m }
+}
+
+// WithColumns makes Marshal write a struct of slices as columns, one tuple per
+// slice index, i.e. struct{H []int; W []int} written as "h=700,w=350
+// h=900,w=450", and Unmarshal read such tuples back into the struct.
+func WithColumns() Option {
+	return func(o *options) { o.columns = true }
+}
+
+// DecodeMode describes how decoded tuples are stored into a slice or an array
+// that already has elements.
+type DecodeMode int
+
+const (
+	// DecodeOverwrite stores tuples from the start of a slice or an array. The
+	// rest of the slice cut off and the rest of the array set to zeros. It's
+	// the default mode.
+	DecodeOverwrite DecodeMode = iota
+	// DecodeAppend stores tuples after the existing elements. Tuples that do
+	// not fit into the array are omitted.
+	DecodeAppend
+	// DecodeMerge updates the existing element that has the same key field
+	// value as the tuple, other tuples are appended. The key field is marked
+	// with the key tag option, i.e. `tuples:"id,key"`. Only the fields present
+	// in the tuple are updated.
+	DecodeMerge
+)
+
+// WithDecodeMode sets the mode of decoding into a slice or an array that
+// already has elements. Default mode is DecodeOverwrite.
+func WithDecodeMode(m DecodeMode) Option {
+	return func(o *options) { o.decodeMode = m }
+}
@@ -0,0 +1,177 @@
+package tuples
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var errTupleCount = errors.New("expected exactly one tuple")
+
+var parseTypes sync.Map // map[reflect.Type]error
+
+// Parse decodes the tuples string s into a slice of T, see Unmarshal. T is
+// a struct, a map keyed by strings or an interface. Field types of T are
+// checked once per type, so an unsupported field type fails even when the
+// tuples do not contain it.
+//
+// Usage:
+//
+//	formats, err := tuples.Parse[Format]("h=700,w=350,f=jpeg h=900,w=450,f=png")
+func Parse[T any](s string, opts ...Option) ([]T, error) {
+	if err := checkParseType(reflect.TypeOf((*T)(nil)).Elem()); err != nil {
+		return nil, err
+	}
+
+	var d decoder
+	if err := d.init([]byte(s), opts...); err != nil {
+		return nil, err
+	}
+
+	out := make([]T, 0)
+	if err := d.unmarshal(&out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// ParseOne decodes the tuples string s that contains exactly one tuple into
+// T, see Parse.
+func ParseOne[T any](s string, opts ...Option) (T, error) {
+	var v T
+
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if err := checkParseType(t); err != nil {
+		return v, err
+	}
+
+	r, err := NewReader(strings.NewReader(s), opts...)
+	if err != nil {
+		return v, err
+	}
+
+	if err := r.Decode(&v); err != nil {
+		if err == io.EOF {
+			err = &UnmarshalError{Err: errTupleCount, Value: s, Type: t}
+		}
+
+		return v, err
+	}
+
+	if r.s.next() {
+		var zero T
+		return zero, &UnmarshalError{Err: errTupleCount, Value: s, Type: t}
+	}
+
+	return v, r.s.err
+}
+
+// MustParse is like Parse but panics if the tuples string cannot be decoded.
+// It simplifies safe initialization of global variables holding parsed
+// tuples.
+//
+// Usage:
+//
+//	var formats = tuples.MustParse[Format]("h=700,w=350,f=jpeg h=900,w=450,f=png")
+func MustParse[T any](s string, opts ...Option) []T {
+	v, err := Parse[T](s, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("tuples: Parse(%q): %v", s, err))
+	}
+
+	return v
+}
+
+// checkParseType checks that the tuples can be decoded into t. The result is
+// cached per type.
+func checkParseType(t reflect.Type) error {
+	if err, ok := parseTypes.Load(t); ok {
+		return errOrNil(err)
+	}
+
+	err, _ := parseTypes.LoadOrStore(t, checkTuplesType(t))
+
+	return errOrNil(err)
+}
+
+func errOrNil(v any) error {
+	err, _ := v.(error)
+	return err
+}
+
+// checkTuplesType checks that t can hold a tuple, i.e. it is a Tuple, a struct
+// with supported field types, a map keyed by strings or an interface.
+func checkTuplesType(t reflect.Type) error {
+	if t == tupleType {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return checkTuplesType(t.Elem())
+	case reflect.Struct:
+		return checkFieldsType(t, map[reflect.Type]bool{})
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String {
+			return checkValueType(t.Elem(), map[reflect.Type]bool{})
+		}
+	case reflect.Interface:
+		return nil
+	}
+
+	return &UnmarshalUnsupportedTypeError{t}
+}
+
+// checkFieldsType checks types of the tagged fields of the struct type t.
+// Visited structs are skipped to allow recursive types.
+func checkFieldsType(t reflect.Type, visited map[reflect.Type]bool) error {
+	if visited[t] {
+		return nil
+	}
+
+	visited[t] = true
+
+	for _, f := range cachedTypeFields(t).fields {
+		ft, _ := t.FieldByName(f.name)
+		if err := checkValueType(ft.Type, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkValueType checks that a field value can be decoded into t.
+func checkValueType(t reflect.Type, visited map[reflect.Type]bool) error {
+	if t == tupleType {
+		return nil
+	}
+
+	if reflect.PointerTo(t).Implements(optionalSetterType) {
+		return checkValueType(reflect.Zero(t).Interface().(optional).optionalType(), visited)
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return checkValueType(t.Elem(), visited)
+	case reflect.Struct:
+		return checkFieldsType(t, visited)
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return &UnmarshalUnsupportedTypeError{t}
+		}
+
+		return checkValueType(t.Elem(), visited)
+	case reflect.String, reflect.Bool, reflect.Interface,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return nil
+	}
+
+	return &UnmarshalUnsupportedTypeError{t}
+}
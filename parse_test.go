@@ -0,0 +1,185 @@
+package tuples_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/antklim/tuples"
+)
+
+type TUnsupported struct {
+	Name string     `tuples:"name"`
+	C    complex128 `tuples:"c"`
+}
+
+type TRecursive struct {
+	Name string      `tuples:"name"`
+	Next *TRecursive `tuples:"next"`
+}
+
+func TestParse(t *testing.T) {
+	out, err := tuples.Parse[T]("name=John,age=23 name=Bob")
+	if err != nil {
+		t.Fatalf("unexpected Parse() error: %v", err)
+	}
+
+	expected := []T{{Name: "John", Age: 23}, {Name: "Bob"}}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("Parse() output:\ngot  %v\nwant %v", out, expected)
+	}
+
+	empty, err := tuples.Parse[T]("")
+	if err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("Parse(\"\") output: %v, %v", empty, err)
+	}
+
+	rec, err := tuples.Parse[TRecursive]("name=a,next=(name=b)")
+	if err != nil {
+		t.Fatalf("unexpected Parse() error: %v", err)
+	}
+
+	if len(rec) != 1 || rec[0].Next == nil || rec[0].Next.Name != "b" {
+		t.Errorf("Parse() output: %+v", rec)
+	}
+}
+
+func TestParseTuple(t *testing.T) {
+	out, err := tuples.Parse[tuples.Tuple]("h=1,w=2 h=3")
+	if err != nil {
+		t.Fatalf("unexpected Parse() error: %v", err)
+	}
+
+	expected := []tuples.Tuple{{{Key: "h", Value: "1"}, {Key: "w", Value: "2"}}, {{Key: "h", Value: "3"}}}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("Parse() output:\ngot  %v\nwant %v", out, expected)
+	}
+
+	type nested struct {
+		Size tuples.Tuple `tuples:"size"`
+	}
+
+	one, err := tuples.ParseOne[nested]("size=(h=1,w=2)")
+	if err != nil {
+		t.Fatalf("unexpected ParseOne() error: %v", err)
+	}
+
+	if got := one.Size.String(); got != "h=1,w=2" {
+		t.Errorf("ParseOne() output: %s, want h=1,w=2", got)
+	}
+}
+
+func TestParseFails(t *testing.T) {
+	testCases := []struct {
+		desc string
+		f    func() error
+		err  error
+	}{
+		{
+			desc: "Unsupported field type",
+			f: func() error {
+				_, err := tuples.Parse[TUnsupported]("name=John")
+				return err
+			},
+			err: &tuples.UnmarshalUnsupportedTypeError{Type: reflect.TypeOf(complex128(0))},
+		},
+		{
+			desc: "Unsupported element type",
+			f: func() error {
+				_, err := tuples.Parse[int]("a=1")
+				return err
+			},
+			err: &tuples.UnmarshalUnsupportedTypeError{Type: reflect.TypeOf(1)},
+		},
+		{
+			desc: "Unsupported map key",
+			f: func() error {
+				_, err := tuples.Parse[map[int]string]("1=a")
+				return err
+			},
+			err: &tuples.UnmarshalUnsupportedTypeError{Type: reflect.TypeOf(map[int]string{})},
+		},
+		{
+			desc: "Invalid value",
+			f: func() error {
+				_, err := tuples.Parse[T]("age=x")
+				return err
+			},
+			err: &tuples.UnmarshalError{Value: "x", Type: reflect.TypeOf(1)},
+		},
+		{
+			desc: "Invalid options",
+			f: func() error {
+				_, err := tuples.Parse[T]("age=1", tuples.WithKeyValueDelimiter(','))
+				return err
+			},
+			err: errors.New("tuples: invalid delimiters: fields and key-value delimiters are equal"),
+		},
+	}
+
+	for tI, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			if err := tC.f(); !eqErrors(err, tC.err) {
+				t.Errorf("#%d: error mismatch:\ngot  %v\nwant %v", tI, err, tC.err)
+			}
+		})
+	}
+}
+
+func TestParseOne(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		out  map[string]any
+		err  error
+	}{
+		{
+			desc: "One tuple",
+			in:   "h=700,size=(w=1)",
+			out:  map[string]any{"h": "700", "size": map[string]any{"w": "1"}},
+		},
+		{
+			desc: "No tuples",
+			in:   "",
+			err:  &tuples.UnmarshalError{Value: "", Type: reflect.TypeOf(map[string]any{})},
+		},
+		{
+			desc: "Many tuples",
+			in:   "h=700 h=900",
+			err:  &tuples.UnmarshalError{Value: "h=700 h=900", Type: reflect.TypeOf(map[string]any{})},
+		},
+		{
+			desc: "Invalid tuple",
+			in:   "h",
+			err:  errors.New("tuples: scan failed: tuple #1 invalid field #1"),
+		},
+	}
+
+	for tI, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			out, err := tuples.ParseOne[map[string]any](tC.in)
+			if !eqErrors(err, tC.err) {
+				t.Errorf("#%d: ParseOne() error mismatch:\ngot  %v\nwant %v", tI, err, tC.err)
+			}
+
+			if !reflect.DeepEqual(out, tC.out) {
+				t.Errorf("#%d: ParseOne() output:\ngot  %v\nwant %v", tI, out, tC.out)
+			}
+		})
+	}
+}
+
+func TestMustParse(t *testing.T) {
+	out := tuples.MustParse[T]("name=John")
+	if expected := []T{{Name: "John"}}; !reflect.DeepEqual(out, expected) {
+		t.Errorf("MustParse() output:\ngot  %v\nwant %v", out, expected)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("MustParse() did not panic")
+		}
+	}()
+
+	tuples.MustParse[T]("age=x")
+}
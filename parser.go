@@ -0,0 +1,324 @@
+package tuples
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	tupleOpen  = '('
+	tupleClose = ')'
+	listOpen   = '['
+	listClose  = ']'
+	valueQuote = '"'
+)
+
+var errInvalidField = errors.New("invalid field")
+
+type nodeKind int
+
+const (
+	nodeValue nodeKind = iota // plain value, i.e. "h=700"
+	nodeBare                  // bare key, i.e. "ro"
+	nodeTuple                 // nested tuple, i.e. "size=(h=700,w=350)"
+	nodeList                  // list, i.e. "sizes=[(h=1,w=2),(h=3,w=4)]"
+	nodeEmpty                 // empty positional field, i.e. the second one in "700,,jpeg"
+)
+
+// node is a scanned tuple field. List items are nodes without a key. Fields
+// of a nested tuple and items of a list are stored in nodes. Value keeps the
+// value text as it appears in the tuple, i.e. "(h=700,w=350)" for a nested
+// tuple.
+type node struct {
+	kind  nodeKind
+	key   string
+	value string
+	nodes []node
+
+	quoted bool // value in quotes, taken as is, i.e. it's not split into a list
+
+	positional bool // top level field of a positional tuple, keyed by position
+}
+
+// isBare reports whether the scanned field is a bare key.
+func isBare(n node) bool {
+	return n.kind == nodeBare
+}
+
+// isEmpty reports whether the scanned field is an empty field of a positional
+// or header tuple. It only keeps the position of the next fields.
+func isEmpty(n node) bool {
+	return n.kind == nodeEmpty
+}
+
+// fieldValue returns the value of the scanned field. Bare keys have an empty
+// value.
+func fieldValue(n node) string {
+	return n.value
+}
+
+// parser parses a tuple text into fields. Nested tuples and lists are parsed
+// recursively.
+type parser struct {
+	s     string
+	i     int
+	opts  scannerOptions
+	field int // number of the top level field being parsed, used in errors
+}
+
+// parseTuple parses a tuple text. It returns the number of the invalid field
+// along with the error.
+func parseTuple(s string, opts scannerOptions) ([]node, int, error) {
+	p := parser{s: s, opts: opts}
+
+	if opts.positional || opts.header {
+		flds, err := p.positional()
+		return flds, p.field, err
+	}
+
+	flds, err := p.fields(0)
+	if err == nil && !p.eof() {
+		err = errInvalidField
+	}
+
+	return flds, p.field, err
+}
+
+func (p *parser) eof() bool {
+	return p.i >= len(p.s)
+}
+
+func (p *parser) peek() rune {
+	if p.eof() {
+		return utf8.RuneError
+	}
+
+	r, _ := utf8.DecodeRuneInString(p.s[p.i:])
+
+	return r
+}
+
+func (p *parser) skip() {
+	_, size := utf8.DecodeRuneInString(p.s[p.i:])
+	p.i += size
+}
+
+// scan moves along the text until stop returns true or the text is over. It
+// returns the scanned text.
+func (p *parser) scan(stop func(rune) bool) string {
+	start := p.i
+	for !p.eof() && !stop(p.peek()) {
+		p.skip()
+	}
+
+	return p.s[start:p.i]
+}
+
+// fields parses fields until the end of the text on the top level or until
+// the closing bracket of a nested tuple. Empty fields are skipped.
+func (p *parser) fields(depth int) ([]node, error) {
+	var flds []node
+
+	for !p.eof() {
+		r := p.peek()
+		if r == p.opts.fd {
+			p.skip()
+			continue
+		}
+
+		if depth > 0 && r == tupleClose {
+			break
+		}
+
+		if depth == 0 {
+			p.field++
+		}
+
+		fld, err := p.keyValue(depth)
+		if err != nil {
+			return nil, err
+		}
+
+		flds = append(flds, fld)
+
+		if !p.eof() && p.peek() != p.opts.fd && (depth == 0 || p.peek() != tupleClose) {
+			return nil, errInvalidField
+		}
+	}
+
+	return flds, nil
+}
+
+// positional parses top level fields that are values without keys, i.e.
+// "700,350,jpeg". Every field gets its position as the key. Empty fields are
+// kept as empty values to preserve positions.
+func (p *parser) positional() ([]node, error) {
+	var flds []node
+
+	for !p.eof() {
+		p.field++
+
+		fld := node{kind: nodeEmpty}
+		if p.peek() != p.opts.fd {
+			var err error
+			if fld, err = p.value(0); err != nil {
+				return nil, err
+			}
+		}
+
+		fld.key, fld.positional = strconv.Itoa(len(flds)), true
+		flds = append(flds, fld)
+
+		if p.eof() {
+			break
+		}
+
+		if p.peek() != p.opts.fd {
+			return nil, errInvalidField
+		}
+
+		p.skip()
+	}
+
+	return flds, nil
+}
+
+func (p *parser) keyValue(depth int) (node, error) {
+	key := p.scan(func(r rune) bool {
+		return r == p.opts.kvd || r == p.opts.fd || isBracket(r)
+	})
+
+	if key == "" {
+		return node{}, errInvalidField
+	}
+
+	if p.eof() || p.peek() != p.opts.kvd {
+		if p.opts.bare && (p.eof() || p.peek() == p.opts.fd || (depth > 0 && p.peek() == tupleClose)) {
+			return node{kind: nodeBare, key: key}, nil
+		}
+
+		return node{}, errInvalidField
+	}
+
+	p.skip()
+
+	n, err := p.value(depth)
+	n.key = key
+
+	return n, err
+}
+
+// value parses a plain value, a nested tuple or a list.
+func (p *parser) value(depth int) (node, error) {
+	start := p.i
+
+	switch p.peek() {
+	case tupleOpen:
+		p.skip()
+
+		flds, err := p.fields(depth + 1)
+		if err != nil {
+			return node{}, err
+		}
+
+		if p.peek() != tupleClose {
+			return node{}, errInvalidField
+		}
+
+		p.skip()
+
+		return node{kind: nodeTuple, value: p.s[start:p.i], nodes: flds}, nil
+	case listOpen:
+		p.skip()
+
+		items, err := p.items(depth + 1)
+		if err != nil {
+			return node{}, err
+		}
+
+		p.skip()
+
+		return node{kind: nodeList, value: p.s[start:p.i], nodes: items}, nil
+	case valueQuote:
+		val, err := p.quoted()
+		if err != nil {
+			return node{}, err
+		}
+
+		return node{kind: nodeValue, value: val, quoted: true}, nil
+	}
+
+	// Brackets are allowed inside a top level value, i.e. "note=a(b)".
+	val := p.scan(func(r rune) bool {
+		return r == p.opts.fd || r == p.opts.kvd || (depth > 0 && isBracket(r))
+	})
+
+	if val == "" || (!p.eof() && p.peek() == p.opts.kvd) {
+		return node{}, errInvalidField
+	}
+
+	return node{kind: nodeValue, value: val}, nil
+}
+
+// quoted parses a value in double quotes, i.e. "a b" in `note="a b"`. A doubled
+// quote inside the value stands for one quote character.
+func (p *parser) quoted() (string, error) {
+	p.skip()
+
+	var b strings.Builder
+
+	for !p.eof() {
+		r := p.peek()
+		p.skip()
+
+		if r != valueQuote {
+			b.WriteRune(r)
+			continue
+		}
+
+		if p.eof() || p.peek() != valueQuote {
+			return b.String(), nil
+		}
+
+		b.WriteRune(valueQuote)
+		p.skip()
+	}
+
+	return "", errInvalidField
+}
+
+// items parses list items until the closing bracket. Empty items are skipped.
+func (p *parser) items(depth int) ([]node, error) {
+	var items []node
+
+	for {
+		if p.eof() {
+			return nil, errInvalidField
+		}
+
+		switch p.peek() {
+		case listClose:
+			return items, nil
+		case p.opts.fd:
+			p.skip()
+			continue
+		}
+
+		item, err := p.value(depth)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+
+		if !p.eof() && p.peek() != p.opts.fd && p.peek() != listClose {
+			return nil, errInvalidField
+		}
+	}
+}
+
+func isBracket(r rune) bool {
+	return r == tupleOpen || r == tupleClose || r == listOpen || r == listClose
+}
@@ -0,0 +1,33 @@
+package tuples
+
+import (
+	"strings"
+	"unicode"
+)
+
+// needsQuote reports whether the value s must be quoted to be read back, i.e.
+// it is empty, starts with a quote or contains whitespace, fields or key-value
+// delimiter or brackets.
+func needsQuote(s string, fd, kvd rune) bool {
+	if s == "" || s[0] == valueQuote {
+		return true
+	}
+
+	return strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == fd || r == kvd || isBracket(r)
+	}) >= 0
+}
+
+// quote returns s in double quotes. Quotes inside s are doubled.
+func quote(s string) string {
+	return string(valueQuote) + strings.ReplaceAll(s, string(valueQuote), `""`) + string(valueQuote)
+}
+
+// quoteValue quotes the value s when it's needed.
+func (o *options) quoteValue(s string) string {
+	if needsQuote(s, o.fieldsDelimiter, o.keyValDelimiter) {
+		return quote(s)
+	}
+
+	return s
+}
@@ -2,39 +2,99 @@ package tuples
 
 import (
 	"io"
+	"reflect"
 	"strings"
 )
 
 // Reader describes a tuples reader.
 type Reader struct {
-	s *scanner
+	s    *scanner
+	opts options
+	keys []string // keys of the first tuple, used to check the schema
 }
 
 // NewReader creates a new instance of the Reader.
 // If reader creation fails it returns error.
 func NewReader(r io.Reader, opts ...ReaderOption) (*Reader, error) {
-	ropts := defaultReaderOptions
-	for _, opt := range opts {
-		opt(&ropts)
-	}
+	ropts := newOptions(opts)
 
-	s, err := newScanner(r,
-		withFieldsDelimiter(ropts.fieldsDelimiter),
-		withKeyValueDelimiter(ropts.keyValDelimiter))
+	s, err := newScanner(r, ropts.scannerOptions()...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Reader{s}, nil
+	return &Reader{s: s, opts: ropts}, nil
 }
 
 // Read reads one tuple at a time and returns fields values in the order
 // they appear in the string. It returns error when read fails or when
-// reached the end of the tuples input.
+// reached the end of the tuples input. Bare keys have an empty value.
 func (r *Reader) Read() ([]string, error) {
 	return r.readTuple()
 }
 
+// Decode reads one tuple and stores it in the value pointed to by v. v is
+// a pointer to a struct, a map or an interface. Fields are mapped the same
+// way as by Unmarshal. It returns io.EOF when reached the end of the tuples
+// input.
+//
+// Usage:
+//
+//	r, err := NewReader(strings.NewReader("h=700,w=350 h=900,w=450"))
+//	for {
+//		var f Format
+//		if err := r.Decode(&f); err == io.EOF {
+//			break
+//		} else if err != nil {
+//			return err
+//		}
+//		fmt.Println(f)
+//	}
+func (r *Reader) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+
+	if !r.s.next() {
+		return r.eof()
+	}
+
+	tuple, err := r.s.tuple()
+	if err != nil {
+		return err
+	}
+
+	if err := r.checkSchema(tuple); err != nil {
+		return err
+	}
+
+	d := decoder{s: r.s, opts: r.opts}
+
+	return d.tuple(indirect(rv), tuple)
+}
+
+// Skip skips n tuples without parsing them. It returns io.EOF when the input
+// is over before n tuples skipped.
+func (r *Reader) Skip(n int) error {
+	if !r.s.nextTimes(n) {
+		return r.eof()
+	}
+
+	return nil
+}
+
+// Header returns the keys declared by the header tuple, i.e. [h w f] for
+// "@h,w,f 700,350,jpeg". It reads the header tuple if it has not been read
+// yet. Header returns nil keys without the WithHeader option.
+func (r *Reader) Header() ([]string, error) {
+	if !r.s.readHeader() && r.s.err != nil {
+		return nil, r.s.err
+	}
+
+	return r.s.header, nil
+}
+
 // ReadAll reads all tuples from the input. It returns a slice of tuples values.
 // It returns error when reader initialisation failed or read process failed.
 func (r *Reader) ReadAll() (tuples [][]string, err error) {
@@ -52,6 +112,42 @@ func (r *Reader) ReadAll() (tuples [][]string, err error) {
 	}
 }
 
+// ReadMap reads one tuple at a time and returns its fields as a map of keys
+// to values. Repeated keys handled according to the duplicate keys policy.
+// A map holds one value per key, so ReadMap fails on repeated keys with
+// DuplicateKeysCollect policy.
+func (r *Reader) ReadMap() (map[string]string, error) {
+	if !r.s.next() {
+		return nil, r.eof()
+	}
+
+	tuple, err := r.s.tuple()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.checkSchema(tuple); err != nil {
+		return nil, err
+	}
+
+	policy := r.opts.duplicateKeys
+	if policy == DuplicateKeysCollect {
+		policy = DuplicateKeysError
+	}
+
+	tuple, err = dedupe(tuple, policy, r.s.pos)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]string, len(tuple))
+	for _, field := range tuple {
+		m[field.key] = fieldValue(field)
+	}
+
+	return m, nil
+}
+
 func (r *Reader) readTuple() ([]string, error) {
 	if r.s.next() {
 		tuple, err := r.s.tuple()
@@ -59,20 +155,50 @@ func (r *Reader) readTuple() ([]string, error) {
 			return nil, err
 		}
 
+		if err := r.checkSchema(tuple); err != nil {
+			return nil, err
+		}
+
 		var fieldValues []string
 		for _, field := range tuple {
-			fieldValues = append(fieldValues, field[idxVal])
+			fieldValues = append(fieldValues, fieldValue(field))
 		}
 
 		return fieldValues, nil
 	}
 
-	err := r.s.err
-	if err == nil {
-		err = io.EOF
+	return nil, r.eof()
+}
+
+func (r *Reader) readFields() ([]Field, error) {
+	if !r.s.next() {
+		return nil, r.eof()
 	}
 
-	return nil, err
+	tuple, err := r.s.tuple()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.checkSchema(tuple); err != nil {
+		return nil, err
+	}
+
+	flds := make([]Field, 0, len(tuple))
+	for _, field := range tuple {
+		flds = append(flds, nodeField(field, r.s.opts))
+	}
+
+	return flds, nil
+}
+
+// eof returns the scanner error or io.EOF when the input is over.
+func (r *Reader) eof() error {
+	if r.s.err != nil {
+		return r.s.err
+	}
+
+	return io.EOF
 }
 
 // ReadString reads all tuples from the string. It returns a slice of tuples
@@ -97,29 +223,3 @@ func ReadString(s string, opts ...ReaderOption) ([][]string, error) {
 
 	return r.ReadAll()
 }
-
-type readerOptions struct {
-	fieldsDelimiter rune
-	keyValDelimiter rune
-}
-
-// ReaderOption describes a reader option, i.e fields delimiter, key-value
-// delimiter, etc.
-type ReaderOption func(*readerOptions)
-
-// WithFieldsDelimiter sets a custom fields delimiter option for reader.
-// Default delimiter is ','.
-func WithFieldsDelimiter(d rune) ReaderOption {
-	return func(ro *readerOptions) { ro.fieldsDelimiter = d }
-}
-
-// WithFieldsDelimiter sets a custom key-value delimiter option for reader.
-// Default delimiter is '='.
-func WithKeyValueDelimiter(d rune) ReaderOption {
-	return func(ro *readerOptions) { ro.keyValDelimiter = d }
-}
-
-var defaultReaderOptions = readerOptions{
-	fieldsDelimiter: ',',
-	keyValDelimiter: '=',
-}
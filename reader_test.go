@@ -74,6 +74,7 @@ type readTest struct {
 
 	fDelim  rune
 	kvDelim rune
+	opts    []tuples.ReaderOption
 }
 
 var readTests = []readTest{
@@ -114,6 +115,36 @@ var readTests = []readTest{
 		in:   "fname,lname=Doe",
 		err:  errors.New("tuples: scan failed: tuple #1 invalid field #1"),
 	},
+	{
+		desc: "BareKeys",
+		in:   "ro,noexec,uid=1000 rw,uid=0",
+		out:  [][]string{{"", "", "1000"}, {"", "0"}},
+		opts: []tuples.ReaderOption{tuples.WithBareKeys()},
+	},
+	{
+		desc: "Header",
+		in:   "@h,w,f 700,,jpeg 900,450",
+		out:  [][]string{{"700", "", "jpeg"}, {"900", "450"}},
+		opts: []tuples.ReaderOption{tuples.WithHeader()},
+	},
+	{
+		desc: "InvalidHeader",
+		in:   "h=700,w=350",
+		err:  errors.New("tuples: scan failed: tuple #1 invalid header"),
+		opts: []tuples.ReaderOption{tuples.WithHeader()},
+	},
+	{
+		desc: "SameOrder",
+		in:   "h=700,w=350 h=900,w=450,h=1",
+		out:  [][]string{{"700", "350"}, {"900", "450", "1"}},
+		opts: []tuples.ReaderOption{tuples.WithSchema(tuples.SchemaSameOrder)},
+	},
+	{
+		desc: "Positional",
+		in:   "700,,jpeg 900,450",
+		out:  [][]string{{"700", "", "jpeg"}, {"900", "450"}},
+		opts: []tuples.ReaderOption{tuples.WithPositional()},
+	},
 }
 
 func newReader(rt readTest) (*tuples.Reader, error) {
@@ -127,6 +158,8 @@ func newReader(rt readTest) (*tuples.Reader, error) {
 		opts = append(opts, tuples.WithKeyValueDelimiter(rt.kvDelim))
 	}
 
+	opts = append(opts, rt.opts...)
+
 	r, err := tuples.NewReader(strings.NewReader(rt.in), opts...)
 	if err != nil {
 		return nil, err
@@ -209,6 +242,8 @@ func TestReadString(t *testing.T) {
 			opts = append(opts, tuples.WithKeyValueDelimiter(tC.kvDelim))
 		}
 
+		opts = append(opts, tC.opts...)
+
 		out, err := tuples.ReadString(tC.in, opts...)
 		if !eqErrors(err, tC.err) {
 			t.Errorf("#%d: ReadString() error mismatch:\ngot  %v\nwant %v", tI, err, tC.err)
@@ -249,3 +284,347 @@ func TestReadStringFails(t *testing.T) {
 		})
 	}
 }
+
+type readMapTest struct {
+	desc string
+	in   string
+	out  []map[string]string
+	err  error
+	opts []tuples.ReaderOption
+}
+
+var readMapTests = []readMapTest{
+	{
+		desc: "Simple",
+		in:   "fname=John,lname=Doe fname=Bob",
+		out:  []map[string]string{{"fname": "John", "lname": "Doe"}, {"fname": "Bob"}},
+	},
+	{
+		desc: "LastWins",
+		in:   "fname=John,fname=Bob",
+		out:  []map[string]string{{"fname": "Bob"}},
+	},
+	{
+		desc: "FirstWins",
+		in:   "fname=John,fname=Bob",
+		out:  []map[string]string{{"fname": "John"}},
+		opts: []tuples.ReaderOption{tuples.WithDuplicateKeys(tuples.DuplicateKeysFirstWins)},
+	},
+	{
+		desc: "Error",
+		in:   "fname=John fname=John,fname=Bob",
+		out:  []map[string]string{{"fname": "John"}},
+		err:  errors.New(`tuples: tuple #2 duplicate key "fname"`),
+		opts: []tuples.ReaderOption{tuples.WithDuplicateKeys(tuples.DuplicateKeysError)},
+	},
+	{
+		desc: "Collect",
+		in:   "fname=John,fname=Bob",
+		err:  errors.New(`tuples: tuple #1 duplicate key "fname"`),
+		opts: []tuples.ReaderOption{tuples.WithDuplicateKeys(tuples.DuplicateKeysCollect)},
+	},
+	{
+		desc: "BareKeys",
+		in:   "ro,uid=0",
+		out:  []map[string]string{{"ro": "", "uid": "0"}},
+		opts: []tuples.ReaderOption{tuples.WithBareKeys()},
+	},
+	{
+		desc: "Header",
+		in:   "@h,w,f 700,350,jpeg 900",
+		out:  []map[string]string{{"h": "700", "w": "350", "f": "jpeg"}, {"h": "900"}},
+		opts: []tuples.ReaderOption{tuples.WithHeader()},
+	},
+	{
+		desc: "SameKeys",
+		in:   "h=700,w=350 w=450,h=900 h=1",
+		out:  []map[string]string{{"h": "700", "w": "350"}, {"h": "900", "w": "450"}},
+		err:  errors.New(`tuples: tuple #3 schema mismatch: missing keys ["w"]`),
+		opts: []tuples.ReaderOption{tuples.WithSchema(tuples.SchemaSameKeys)},
+	},
+	{
+		desc: "SameKeysExtra",
+		in:   "h=700,w=350 h=900,f=png",
+		out:  []map[string]string{{"h": "700", "w": "350"}},
+		err:  &tuples.SchemaError{Tuple: 2, Missing: []string{"w"}, Extra: []string{"f"}},
+		opts: []tuples.ReaderOption{tuples.WithSchema(tuples.SchemaSameKeys)},
+	},
+	{
+		desc: "SameOrder",
+		in:   "h=700,w=350 w=450,h=900",
+		out:  []map[string]string{{"h": "700", "w": "350"}},
+		err:  errors.New("tuples: tuple #2 schema mismatch: keys order mismatch"),
+		opts: []tuples.ReaderOption{tuples.WithSchema(tuples.SchemaSameOrder)},
+	},
+	{
+		desc: "SameKeysHeader",
+		in:   "@h,w 700,350 900",
+		out:  []map[string]string{{"h": "700", "w": "350"}},
+		err:  errors.New(`tuples: tuple #3 schema mismatch: missing keys ["w"]`),
+		opts: []tuples.ReaderOption{tuples.WithHeader(), tuples.WithSchema(tuples.SchemaSameKeys)},
+	},
+	{
+		desc: "HeaderExtraValue",
+		in:   "@h,w 700,350,jpeg",
+		err:  errors.New("tuples: scan failed: tuple #2 invalid field #3"),
+		opts: []tuples.ReaderOption{tuples.WithHeader()},
+	},
+}
+
+func TestReadMap(t *testing.T) {
+	for tI, tC := range readMapTests {
+		t.Run(tC.desc, func(t *testing.T) {
+			r, err := tuples.NewReader(strings.NewReader(tC.in), tC.opts...)
+			if err != nil {
+				t.Fatalf("#%d: unexpected NewReader() error: %v", tI, err)
+			}
+
+			var out []map[string]string
+			for {
+				m, err := r.ReadMap()
+				if err == io.EOF {
+					break
+				}
+
+				if err != nil {
+					if !eqErrors(err, tC.err) {
+						t.Errorf("#%d: ReadMap() error mismatch:\ngot  %v\nwant %v", tI, err, tC.err)
+					}
+					break
+				}
+
+				out = append(out, m)
+			}
+
+			if !reflect.DeepEqual(out, tC.out) {
+				t.Errorf("#%d: ReadMap() output:\ngot  %v\nwant %v", tI, out, tC.out)
+			}
+		})
+	}
+}
+
+func TestHeader(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		opts []tuples.ReaderOption
+		out  []string
+		err  error
+	}{
+		{
+			desc: "Declared keys",
+			in:   "@h,w,f 700,350,jpeg",
+			opts: []tuples.ReaderOption{tuples.WithHeader()},
+			out:  []string{"h", "w", "f"},
+		},
+		{
+			desc: "Custom delimiter",
+			in:   "@h;w 700;350",
+			opts: []tuples.ReaderOption{tuples.WithHeader(), tuples.WithFieldsDelimiter(';')},
+			out:  []string{"h", "w"},
+		},
+		{
+			desc: "Empty input",
+			opts: []tuples.ReaderOption{tuples.WithHeader()},
+		},
+		{
+			desc: "Without header option",
+			in:   "h=700",
+		},
+		{
+			desc: "Duplicate key",
+			in:   "@h,w,h 700,350,1",
+			opts: []tuples.ReaderOption{tuples.WithHeader()},
+			err:  errors.New("tuples: scan failed: tuple #1 invalid header"),
+		},
+		{
+			desc: "Key-value in header",
+			in:   "@h=1,w",
+			opts: []tuples.ReaderOption{tuples.WithHeader()},
+			err:  errors.New("tuples: scan failed: tuple #1 invalid header"),
+		},
+	}
+
+	for tI, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			r, err := tuples.NewReader(strings.NewReader(tC.in), tC.opts...)
+			if err != nil {
+				t.Fatalf("#%d: unexpected NewReader() error: %v", tI, err)
+			}
+
+			out, err := r.Header()
+			if !eqErrors(err, tC.err) {
+				t.Errorf("#%d: Header() error mismatch:\ngot  %v\nwant %v", tI, err, tC.err)
+			}
+
+			if !reflect.DeepEqual(out, tC.out) {
+				t.Errorf("#%d: Header() output:\ngot  %v\nwant %v", tI, out, tC.out)
+			}
+
+			if err != nil {
+				return
+			}
+
+			// The header tuple is not returned as values.
+			if tuple, err := r.Read(); err == nil && len(tC.out) > 0 && tuple[0] != "700" {
+				t.Errorf("#%d: Read() after Header() output: %v", tI, tuple)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		opts []tuples.ReaderOption
+		ptr  func() any
+		out  []any
+		err  error
+	}{
+		{
+			desc: "Struct",
+			in:   "name=John,age=23 name=Bob",
+			ptr:  func() any { return new(T) },
+			out:  []any{&T{Name: "John", Age: 23}, &T{Name: "Bob"}},
+		},
+		{
+			desc: "Map",
+			in:   "h=700,w=350 f=png",
+			ptr:  func() any { return new(map[string]string) },
+			out:  []any{&map[string]string{"h": "700", "w": "350"}, &map[string]string{"f": "png"}},
+		},
+		{
+			desc: "Interface",
+			in:   "kind=resize,w=10 f=png,size=(h=1)",
+			opts: []tuples.ReaderOption{tuples.WithKind("resize", TResize{})},
+			ptr:  func() any { return new(any) },
+			out:  []any{ptrTo[any](TResize{W: 10}), ptrTo[any](map[string]any{"f": "png", "size": map[string]any{"h": "1"}})},
+		},
+		{
+			desc: "Header",
+			in:   "@name,age John,23",
+			opts: []tuples.ReaderOption{tuples.WithHeader()},
+			ptr:  func() any { return new(T) },
+			out:  []any{&T{Name: "John", Age: 23}},
+		},
+		{
+			desc: "Invalid value",
+			in:   "age=x",
+			ptr:  func() any { return new(T) },
+			err:  &tuples.UnmarshalError{Value: "x", Type: reflect.TypeOf(1)},
+		},
+		{
+			desc: "Unsupported destination",
+			in:   "age=1",
+			ptr:  func() any { return new(int) },
+			err:  &tuples.UnmarshalError{Value: "tuple", Type: reflect.TypeOf(1)},
+		},
+		{
+			desc: "Not a pointer",
+			in:   "age=1",
+			ptr:  func() any { return T{} },
+			err:  &tuples.InvalidUnmarshalError{Type: reflect.TypeOf(T{})},
+		},
+	}
+
+	for tI, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			r, err := tuples.NewReader(strings.NewReader(tC.in), tC.opts...)
+			if err != nil {
+				t.Fatalf("#%d: unexpected NewReader() error: %v", tI, err)
+			}
+
+			var out []any
+			for {
+				v := tC.ptr()
+				err := r.Decode(v)
+				if err == io.EOF {
+					break
+				}
+
+				if err != nil {
+					if !eqErrors(err, tC.err) {
+						t.Errorf("#%d: Decode() error mismatch:\ngot  %v\nwant %v", tI, err, tC.err)
+					}
+					break
+				}
+
+				out = append(out, v)
+			}
+
+			if !reflect.DeepEqual(out, tC.out) {
+				t.Errorf("#%d: Decode() output:\ngot  %v\nwant %v", tI, out, tC.out)
+			}
+		})
+	}
+}
+
+func TestSkip(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		opts []tuples.ReaderOption
+		n    int
+		out  [][]string
+		err  error
+	}{
+		{
+			desc: "Skips tuples",
+			in:   "h=1 h=2 h=3",
+			n:    2,
+			out:  [][]string{{"3"}},
+		},
+		{
+			desc: "Skips invalid tuples",
+			in:   "h h=2",
+			n:    1,
+			out:  [][]string{{"2"}},
+		},
+		{
+			desc: "Skips nothing",
+			in:   "h=1",
+			out:  [][]string{{"1"}},
+		},
+		{
+			desc: "Skips after header",
+			in:   "@h 1 2",
+			opts: []tuples.ReaderOption{tuples.WithHeader()},
+			n:    1,
+			out:  [][]string{{"2"}},
+		},
+		{
+			desc: "Skips all tuples",
+			in:   "h=1 h=2",
+			n:    2,
+		},
+		{
+			desc: "Skips past the end",
+			in:   "h=1",
+			n:    2,
+			err:  io.EOF,
+		},
+	}
+
+	for tI, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			r, err := tuples.NewReader(strings.NewReader(tC.in), tC.opts...)
+			if err != nil {
+				t.Fatalf("#%d: unexpected NewReader() error: %v", tI, err)
+			}
+
+			if err := r.Skip(tC.n); err != tC.err {
+				t.Fatalf("#%d: Skip() error mismatch:\ngot  %v\nwant %v", tI, err, tC.err)
+			}
+
+			out, err := r.ReadAll()
+			if err != nil {
+				t.Fatalf("#%d: unexpected ReadAll() error: %v", tI, err)
+			}
+
+			if !reflect.DeepEqual(out, tC.out) {
+				t.Errorf("#%d: ReadAll() output:\ngot  %v\nwant %v", tI, out, tC.out)
+			}
+		})
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -13,6 +13,9 @@ var (
 	errEqualDelimiters          = errors.New("fields and key-value delimiters are equal")
 	errInvalidFieldsDelimiter   = errors.New("invalid fields delimiter")
 	errInvalidKeyValueDelimiter = errors.New("invalid key-value delimiter")
+	errInvalidListDelimiter     = errors.New("invalid list delimiter")
+	errListDelimiterConflict    = errors.New("list delimiter equals fields, key-value or tuples delimiter")
+	errHeaderPositional         = errors.New("header and positional tuples cannot be combined")
 )
 
 // ScannerError describes an error that occurred while scanning a tuple.
@@ -42,11 +45,6 @@ func (e *InvalidScannerOptionError) Unwrap() error {
 	return e.err
 }
 
-const (
-	idxKey = iota
-	idxVal
-)
-
 const (
 	scanReady = iota
 	scanTuple
@@ -54,8 +52,13 @@ const (
 )
 
 type scannerOptions struct {
-	fd  rune // fields delimiter
-	kvd rune // key-values delimiter
+	fd   rune // fields delimiter
+	kvd  rune // key-values delimiter
+	ld   rune // list values delimiter, 0 when lists are not split
+	bare bool // allow bare keys, i.e. fields without key-value delimiter
+
+	positional bool // fields are values without keys
+	header     bool // keys are declared once by the header tuple, i.e. "@h,w,f"
 }
 
 func (so *scannerOptions) validate() error {
@@ -71,21 +74,116 @@ func (so *scannerOptions) validate() error {
 		return errInvalidKeyValueDelimiter
 	}
 
+	if so.positional && so.header {
+		return errHeaderPositional
+	}
+
+	if so.ld != 0 {
+		return so.validateListDelimiter(so.ld)
+	}
+
+	return nil
+}
+
+// validateListDelimiter checks that the list delimiter differs from fields,
+// key-value and tuples (whitespace) delimiters.
+func (so *scannerOptions) validateListDelimiter(ld rune) error {
+	if !validDelim(ld) {
+		return errInvalidListDelimiter
+	}
+
+	if ld == so.fd || ld == so.kvd || unicode.IsSpace(ld) {
+		return errListDelimiterConflict
+	}
+
 	return nil
 }
 
 func validDelim(r rune) bool {
-	return r != 0 && utf8.ValidRune(r) && r != utf8.RuneError
+	return r != 0 && utf8.ValidRune(r) && r != utf8.RuneError && !isBracket(r) && r != valueQuote
+}
+
+// scanTuples is a bufio.SplitFunc that splits the input into tuples by
+// whitespace, the same way as bufio.ScanWords. Whitespace inside a quoted
+// value does not split tuples.
+func (so *scannerOptions) scanTuples(data []byte, atEOF bool) (int, []byte, error) {
+	// Skip leading spaces.
+	start := spaceLen(data)
+
+	quoted := false
+	prev := rune(-1)
+
+	for width, i := 0, start; i < len(data); i += width {
+		var r rune
+		r, width = utf8.DecodeRune(data[i:])
+
+		switch {
+		case quoted && r == valueQuote:
+			if i+width == len(data) && !atEOF {
+				// Request more data to tell a closing quote from a doubled one.
+				return start, nil, nil
+			}
+
+			if i+width < len(data) && data[i+width] == valueQuote {
+				width++
+			} else {
+				quoted = false
+			}
+		case quoted:
+		case r == valueQuote && (i == start || so.valueStart(prev)):
+			quoted = true
+		case unicode.IsSpace(r):
+			return i + width, data[start:i], nil
+		}
+
+		prev = r
+	}
+
+	// If we're at EOF, we have a final, non-empty, non-terminated tuple.
+	if atEOF && len(data) > start {
+		return len(data), data[start:], nil
+	}
+
+	// Request more data.
+	return start, nil, nil
+}
+
+// spaceLen returns the length of the leading whitespace of data.
+func spaceLen(data []byte) int {
+	n := 0
+	for n < len(data) {
+		r, width := utf8.DecodeRune(data[n:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+
+		n += width
+	}
+
+	return n
+}
+
+// valueStart reports whether a value starts after the rune r, i.e. r is
+// a delimiter or an opening bracket.
+func (so *scannerOptions) valueStart(r rune) bool {
+	return r == so.kvd || r == so.fd || r == tupleOpen || r == listOpen
 }
 
 var defaultScannerOptions = scannerOptions{fd: ',', kvd: '='}
 
 type scanner struct {
-	s     *bufio.Scanner
-	state int
-	pos   int
-	err   error
-	opts  scannerOptions
+	s      *bufio.Scanner
+	state  int
+	pos    int
+	err    error
+	opts   scannerOptions
+	header []string // keys declared by the header tuple
+	hread  bool     // header tuple has been scanned
+
+	// Scanned tuples given in advance, see newNodesScanner.
+	preset bool
+	nodes  [][]node
+	texts  []string
 }
 
 func newScanner(r io.Reader, opts ...scannerOption) (*scanner, error) {
@@ -99,7 +197,7 @@ func newScanner(r io.Reader, opts ...scannerOption) (*scanner, error) {
 	}
 
 	bufscan := bufio.NewScanner(r)
-	bufscan.Split(bufio.ScanWords)
+	bufscan.Split(sopts.scanTuples)
 
 	s := &scanner{
 		s:    bufscan,
@@ -109,6 +207,12 @@ func newScanner(r io.Reader, opts ...scannerOption) (*scanner, error) {
 	return s, nil
 }
 
+// newNodesScanner creates a scanner over already scanned tuples, i.e. the
+// tuples of a Document. texts are the tuples texts used in errors.
+func newNodesScanner(nodes [][]node, texts []string, opts scannerOptions) *scanner {
+	return &scanner{opts: opts, preset: true, nodes: nodes, texts: texts}
+}
+
 // next moves the scanner along the tuples values. It returns false if scanning
 // finished or error occurred. Call tupple() to get scanned values.
 // next returns false only when the scanner is finished. It means that even
@@ -140,6 +244,15 @@ func (s *scanner) next() bool {
 		s.state = scanTuple
 	}
 
+	if s.preset {
+		return s.nextNodes()
+	}
+
+	if !s.readHeader() {
+		s.state = scanDone
+		return false
+	}
+
 	if !s.s.Scan() {
 		s.state = scanDone
 		if err := s.s.Err(); err != nil {
@@ -152,36 +265,82 @@ func (s *scanner) next() bool {
 	return s.state != scanDone
 }
 
-func (s *scanner) nextTimes(n int) bool {
-	for ; n >= 1 && s.next(); n-- { //nolint:revive
+// readHeader scans the header tuple once when keys are declared by header. It
+// returns false if the input is over or the header is invalid.
+func (s *scanner) readHeader() bool {
+	if !s.opts.header || s.hread {
+		return s.err == nil
 	}
 
-	return s.state != scanDone
+	s.hread = true
+
+	if !s.s.Scan() {
+		if err := s.s.Err(); err != nil {
+			s.err = &ScannerError{err}
+		}
+
+		return false
+	}
+
+	s.pos++
+
+	header, err := parseHeader(s.s.Text(), s.opts)
+	if err != nil {
+		s.err = &ScannerError{fmt.Errorf("tuple #%d %w", s.pos, err)}
+		return false
+	}
+
+	s.header = header
+
+	return true
 }
 
-func (s *scanner) tuple() ([][]string, error) {
-	var tuple [][]string
+// nextNodes moves the scanner along the preset tuples.
+func (s *scanner) nextNodes() bool {
+	if s.pos >= len(s.nodes) {
+		s.state = scanDone
+		return false
+	}
 
-	// It splits "name=John,lname=Doe,age=17" to ["name=John", "lname=Doe", "age=17"].
-	fields := strings.FieldsFunc(s.s.Text(), splitFunc(s.opts.fd))
+	s.pos++
 
-	for i, f := range fields {
-		// It splits "name=John" into ["name", "John"].
-		kv := strings.FieldsFunc(f, splitFunc(s.opts.kvd))
+	return true
+}
 
-		if len(kv) != 2 { //nolint:gomnd
-			s.err = &ScannerError{fmt.Errorf("tuple #%d invalid field #%d", s.pos, i+1)}
-			return nil, s.err
-		}
+// text returns the text of the scanned tuple.
+func (s *scanner) text() string {
+	if s.preset {
+		return s.texts[s.pos-1]
+	}
 
-		tuple = append(tuple, []string{kv[idxKey], kv[idxVal]})
+	return s.s.Text()
+}
+
+func (s *scanner) nextTimes(n int) bool {
+	for ; n >= 1 && s.next(); n-- { //nolint:revive
 	}
 
-	return tuple, nil
+	return s.state != scanDone
 }
 
-func splitFunc(dlm rune) func(rune) bool {
-	return func(r rune) bool { return r == dlm }
+// tuple parses the scanned tuple into fields. Nested tuples and lists are
+// parsed recursively.
+func (s *scanner) tuple() ([]node, error) {
+	if s.preset {
+		return s.nodes[s.pos-1], nil
+	}
+
+	tuple, fieldNum, err := parseTuple(s.s.Text(), s.opts)
+	if err == nil && s.opts.header {
+		fieldNum, err = keyByHeader(tuple, s.header)
+	}
+
+	if err != nil {
+		s.err = &ScannerError{fmt.Errorf("tuple #%d invalid field #%d", s.pos, fieldNum)}
+		return nil, s.err
+	}
+
+	return tuple, nil
 }
 
 type scannerOption func(*scannerOptions)
@@ -193,3 +352,19 @@ func withFieldsDelimiter(d rune) scannerOption {
 func withKeyValueDelimiter(d rune) scannerOption {
 	return func(so *scannerOptions) { so.kvd = d }
 }
+
+func withListDelimiter(d rune) scannerOption {
+	return func(so *scannerOptions) { so.ld = d }
+}
+
+func withBareKeys() scannerOption {
+	return func(so *scannerOptions) { so.bare = true }
+}
+
+func withPositional() scannerOption {
+	return func(so *scannerOptions) { so.positional = true }
+}
+
+func withHeader() scannerOption {
+	return func(so *scannerOptions) { so.header = true }
+}
@@ -13,6 +13,7 @@ type scanTest struct {
 	in   string
 	out  [][][]string // [[[key value], .... pairs of key values is a tuple], ....]
 	err  error
+	opts []scannerOption
 }
 
 var scanTests = []scanTest{
@@ -52,23 +53,55 @@ var scanTests = []scanTest{
 		in:   "fname=John,lname=Doe,dob=2000-01-01 =Bob,lname=Smith,dob=2010-10-10",
 		err:  errors.New("tuples: scan failed: tuple #2 invalid field #1"),
 	},
+	{
+		desc: "Bare keys",
+		in:   "ro,noexec,uid=1000 rw",
+		out:  [][][]string{{{"ro"}, {"noexec"}, {"uid", "1000"}}, {{"rw"}}},
+		opts: []scannerOption{withBareKeys()},
+	},
+	{
+		desc: "Quoted values with whitespace",
+		in:   `note="a b, c=d",f=png  h="" q="say ""hi"" now" in=5",w=1`,
+		out: [][][]string{
+			{{"note", "a b, c=d"}, {"f", "png"}},
+			{{"h", ""}},
+			{{"q", `say "hi" now`}},
+			{{"in", `5"`}, {"w", "1"}},
+		},
+	},
+	{
+		desc: "Unterminated quoted value",
+		in:   `h=1 note="a b`,
+		err:  errors.New("tuples: scan failed: tuple #2 invalid field #1"),
+	},
+	{
+		desc: "Text after quoted value",
+		in:   `note="a"b`,
+		err:  errors.New("tuples: scan failed: tuple #1 invalid field #1"),
+	},
+	{
+		desc: "Bare keys with empty value",
+		in:   "ro,uid=",
+		err:  errors.New("tuples: scan failed: tuple #1 invalid field #2"),
+		opts: []scannerOption{withBareKeys()},
+	},
 }
 
 func TestNext(t *testing.T) {
 	for tI, tC := range scanTests {
 		t.Run(tC.desc, func(t *testing.T) {
-			s, err := newScanner(strings.NewReader(tC.in))
+			s, err := newScanner(strings.NewReader(tC.in), tC.opts...)
 			if err != nil {
 				t.Fatalf("#%d: unexpected newScanner() error: %v", tI, err)
 			}
 
 			var out [][][]string
-			var tuple [][]string
+			var tuple []node
 			// while has next
 			for s.next() {
 				tuple, err = s.tuple()
 				if tuple != nil {
-					out = append(out, tuple)
+					out = append(out, flatTuple(tuple))
 				}
 			}
 
@@ -107,6 +140,150 @@ func TestNext(t *testing.T) {
 	}
 }
 
+// flatTuple converts scanned fields to pairs of key and value. Bare keys
+// converted to a key only.
+func flatTuple(tuple []node) [][]string {
+	var out [][]string
+
+	for _, n := range tuple {
+		if isBare(n) {
+			out = append(out, []string{n.key})
+			continue
+		}
+
+		out = append(out, []string{n.key, fieldValue(n)})
+	}
+
+	return out
+}
+
+type scanNestedTest struct {
+	desc string
+	in   string
+	out  []node
+	err  error
+	opts []scannerOption
+}
+
+var scanNestedTests = []scanNestedTest{
+	{
+		desc: "Nested tuple",
+		in:   "size=(h=700,w=350),f=jpeg",
+		out: []node{
+			{kind: nodeTuple, key: "size", value: "(h=700,w=350)", nodes: []node{
+				{key: "h", value: "700"},
+				{key: "w", value: "350"},
+			}},
+			{key: "f", value: "jpeg"},
+		},
+	},
+	{
+		desc: "List of nested tuples",
+		in:   "sizes=[(h=1,w=2),(h=3,w=4)]",
+		out: []node{
+			{kind: nodeList, key: "sizes", value: "[(h=1,w=2),(h=3,w=4)]", nodes: []node{
+				{kind: nodeTuple, value: "(h=1,w=2)", nodes: []node{{key: "h", value: "1"}, {key: "w", value: "2"}}},
+				{kind: nodeTuple, value: "(h=3,w=4)", nodes: []node{{key: "h", value: "3"}, {key: "w", value: "4"}}},
+			}},
+		},
+	},
+	{
+		desc: "Deeply nested and empty values",
+		in:   "a=(b=[1,[2],()],c=[]),d=(ro)",
+		out: []node{
+			{kind: nodeTuple, key: "a", value: "(b=[1,[2],()],c=[])", nodes: []node{
+				{kind: nodeList, key: "b", value: "[1,[2],()]", nodes: []node{
+					{value: "1"},
+					{kind: nodeList, value: "[2]", nodes: []node{{value: "2"}}},
+					{kind: nodeTuple, value: "()"},
+				}},
+				{kind: nodeList, key: "c", value: "[]"},
+			}},
+			{kind: nodeTuple, key: "d", value: "(ro)", nodes: []node{{kind: nodeBare, key: "ro"}}},
+		},
+		opts: []scannerOption{withBareKeys()},
+	},
+	{
+		desc: "Brackets inside top level value",
+		in:   "note=a(b)c,f=[x]",
+		out: []node{
+			{key: "note", value: "a(b)c"},
+			{kind: nodeList, key: "f", value: "[x]", nodes: []node{{value: "x"}}},
+		},
+	},
+	{
+		desc: "Unclosed nested tuple",
+		in:   "f=jpeg,size=(h=700,w=350",
+		err:  errors.New("tuples: scan failed: tuple #1 invalid field #2"),
+	},
+	{
+		desc: "Unclosed list",
+		in:   "sizes=[(h=1)",
+		err:  errors.New("tuples: scan failed: tuple #1 invalid field #1"),
+	},
+	{
+		desc: "Text after nested tuple",
+		in:   "size=(h=1)x",
+		err:  errors.New("tuples: scan failed: tuple #1 invalid field #1"),
+	},
+	{
+		desc: "Invalid nested field",
+		in:   "f=jpeg,size=(h=1,w)",
+		err:  errors.New("tuples: scan failed: tuple #1 invalid field #2"),
+	},
+	{
+		desc: "Quoted nested values",
+		in:   `size=(n="x y",m=")"),l=["a b",c],t="a|b"`,
+		out: []node{
+			{kind: nodeTuple, key: "size", value: `(n="x y",m=")")`, nodes: []node{
+				{key: "n", value: "x y", quoted: true},
+				{key: "m", value: ")", quoted: true},
+			}},
+			{kind: nodeList, key: "l", value: `["a b",c]`, nodes: []node{{value: "a b", quoted: true}, {value: "c"}}},
+			{key: "t", value: "a|b", quoted: true},
+		},
+		opts: []scannerOption{withListDelimiter('|')},
+	},
+	{
+		desc: "Positional fields",
+		in:   "700,,(h=1),[a,b],",
+		out: []node{
+			{key: "0", value: "700", positional: true},
+			{kind: nodeEmpty, key: "1", positional: true},
+			{kind: nodeTuple, key: "2", value: "(h=1)", nodes: []node{{key: "h", value: "1"}}, positional: true},
+			{kind: nodeList, key: "3", value: "[a,b]", nodes: []node{{value: "a"}, {value: "b"}}, positional: true},
+		},
+		opts: []scannerOption{withPositional()},
+	},
+	{
+		desc: "Key-value in positional fields",
+		in:   "700,f=jpeg",
+		err:  errors.New("tuples: scan failed: tuple #1 invalid field #2"),
+		opts: []scannerOption{withPositional()},
+	},
+}
+
+func TestScanNested(t *testing.T) {
+	for tI, tC := range scanNestedTests {
+		t.Run(tC.desc, func(t *testing.T) {
+			s, err := newScanner(strings.NewReader(tC.in), tC.opts...)
+			if err != nil {
+				t.Fatalf("#%d: unexpected newScanner() error: %v", tI, err)
+			}
+
+			s.next()
+			out, err := s.tuple()
+			if (err == nil) != (tC.err == nil) || (err != nil && err.Error() != tC.err.Error()) {
+				t.Fatalf("#%d: scan tuple() error mismatch:\ngot  %v\nwant %v", tI, err, tC.err)
+			}
+
+			if !reflect.DeepEqual(out, tC.out) {
+				t.Errorf("#%d: scan tuple() output:\ngot  %+v\nwant %+v", tI, out, tC.out)
+			}
+		})
+	}
+}
+
 type nextTest struct {
 	desc  string
 	in    string
@@ -161,7 +338,8 @@ func TestNextTimes(t *testing.T) {
 			}
 
 			hasNext := s.nextTimes(tC.times)
-			out, err := s.tuple()
+			tuple, err := s.tuple()
+			out := flatTuple(tuple)
 
 			if err != nil {
 				t.Errorf("#%d: unexpected scan nextTimes() error: %v", tI, err)
@@ -229,6 +407,51 @@ var scannerOptTests = []scannerOptTest{
 		opts: []scannerOption{withKeyValueDelimiter(utf8.RuneError)},
 		err:  errors.New("tuples: invalid delimiters: invalid key-value delimiter"),
 	},
+	{
+		desc: "scanner with bracket fields delimiter",
+		opts: []scannerOption{withFieldsDelimiter('(')},
+		err:  errors.New("tuples: invalid delimiters: invalid fields delimiter"),
+	},
+	{
+		desc: "scanner with bracket key-value delimiter",
+		opts: []scannerOption{withKeyValueDelimiter(']')},
+		err:  errors.New("tuples: invalid delimiters: invalid key-value delimiter"),
+	},
+	{
+		desc:  "scanner with custom list delimiter",
+		opts:  []scannerOption{withListDelimiter('|')},
+		sopts: scannerOptions{fd: ',', kvd: '=', ld: '|'},
+	},
+	{
+		desc: "scanner with invalid list delimiter",
+		opts: []scannerOption{withListDelimiter(utf8.RuneError)},
+		err:  errors.New("tuples: invalid delimiters: invalid list delimiter"),
+	},
+	{
+		desc: "scanner with list delimiter equal to fields delimiter",
+		opts: []scannerOption{withListDelimiter(',')},
+		err:  errors.New("tuples: invalid delimiters: list delimiter equals fields, key-value or tuples delimiter"),
+	},
+	{
+		desc: "scanner with list delimiter equal to key-value delimiter",
+		opts: []scannerOption{withListDelimiter('=')},
+		err:  errors.New("tuples: invalid delimiters: list delimiter equals fields, key-value or tuples delimiter"),
+	},
+	{
+		desc: "scanner with whitespace list delimiter",
+		opts: []scannerOption{withListDelimiter('\t')},
+		err:  errors.New("tuples: invalid delimiters: list delimiter equals fields, key-value or tuples delimiter"),
+	},
+	{
+		desc: "scanner with quote fields delimiter",
+		opts: []scannerOption{withFieldsDelimiter('"')},
+		err:  errors.New("tuples: invalid delimiters: invalid fields delimiter"),
+	},
+	{
+		desc: "scanner with header and positional tuples",
+		opts: []scannerOption{withHeader(), withPositional()},
+		err:  errors.New("tuples: invalid delimiters: header and positional tuples cannot be combined"),
+	},
 }
 
 func TestScannerOptions(t *testing.T) {
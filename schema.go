@@ -0,0 +1,124 @@
+package tuples
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Schema describes how Reader checks keys of the tuples against the keys of
+// the first tuple.
+type Schema int
+
+const (
+	// SchemaAny accepts tuples with any keys. It's the default.
+	SchemaAny Schema = iota
+	// SchemaSameKeys requires every tuple to have the same key set as the
+	// first tuple, in any order.
+	SchemaSameKeys
+	// SchemaSameOrder requires every tuple to have the same keys as the first
+	// tuple, in the same order.
+	SchemaSameOrder
+)
+
+// SchemaError describes a tuple which keys differ from the keys of the first
+// tuple. Missing and Extra are empty when only the keys order differs.
+type SchemaError struct {
+	Tuple   int
+	Missing []string
+	Extra   []string
+}
+
+func (e *SchemaError) Error() string {
+	var diff []string
+
+	if len(e.Missing) > 0 {
+		diff = append(diff, fmt.Sprintf("missing keys %q", e.Missing))
+	}
+
+	if len(e.Extra) > 0 {
+		diff = append(diff, fmt.Sprintf("extra keys %q", e.Extra))
+	}
+
+	if len(diff) == 0 {
+		diff = append(diff, "keys order mismatch")
+	}
+
+	return fmt.Sprintf("tuples: tuple #%d schema mismatch: %s", e.Tuple, strings.Join(diff, ", "))
+}
+
+// tupleKeys returns the keys of the scanned tuple in the order of their first
+// appearance. Empty fields of positional and header tuples are not keys.
+func tupleKeys(flds []node) []string {
+	keys := make([]string, 0, len(flds))
+	seen := make(map[string]bool, len(flds))
+
+	for _, fld := range flds {
+		if !isEmpty(fld) && !seen[fld.key] {
+			seen[fld.key] = true
+			keys = append(keys, fld.key)
+		}
+	}
+
+	return keys
+}
+
+// diffKeys returns the keys of want that are missing in got and the keys of
+// got that are absent in want.
+func diffKeys(want, got []string) (missing, extra []string) {
+	inWant := make(map[string]bool, len(want))
+	for _, key := range want {
+		inWant[key] = true
+	}
+
+	inGot := make(map[string]bool, len(got))
+	for _, key := range got {
+		inGot[key] = true
+
+		if !inWant[key] {
+			extra = append(extra, key)
+		}
+	}
+
+	for _, key := range want {
+		if !inGot[key] {
+			missing = append(missing, key)
+		}
+	}
+
+	return missing, extra
+}
+
+// checkSchema checks keys of the scanned tuple against the keys of the first
+// tuple according to the schema option.
+func (r *Reader) checkSchema(flds []node) error {
+	if r.opts.schema == SchemaAny {
+		return nil
+	}
+
+	keys := tupleKeys(flds)
+	if r.keys == nil {
+		r.keys = keys
+		return nil
+	}
+
+	missing, extra := diffKeys(r.keys, keys)
+	if len(missing) > 0 || len(extra) > 0 || (r.opts.schema == SchemaSameOrder && !equalKeys(r.keys, keys)) {
+		return &SchemaError{Tuple: r.s.pos, Missing: missing, Extra: extra}
+	}
+
+	return nil
+}
+
+func equalKeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,451 @@
+package tuples
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	errSelectorKey     = errors.New("key expected")
+	errSelectorValue   = errors.New("value expected")
+	errSelectorNumber  = errors.New("number expected")
+	errSelectorList    = errors.New("values list expected")
+	errSelectorUnknown = errors.New("unexpected character")
+)
+
+// SelectorError describes an invalid selector expression. Pos is the byte
+// offset of the error in the expression.
+type SelectorError struct {
+	Expr string
+	Pos  int
+	err  error
+}
+
+func (e *SelectorError) Error() string {
+	return fmt.Sprintf("tuples: invalid selector %q at %d: %s", e.Expr, e.Pos, e.err)
+}
+
+func (e *SelectorError) Unwrap() error {
+	return e.err
+}
+
+type selectorOp int
+
+const (
+	selectExists selectorOp = iota
+	selectNotExists
+	selectEqual
+	selectNotEqual
+	selectIn
+	selectNotIn
+	selectGreater
+	selectGreaterEqual
+	selectLess
+	selectLessEqual
+)
+
+// requirement is one comma separated part of a selector, i.e. "f in (jpeg,png)".
+type requirement struct {
+	key    string
+	op     selectorOp
+	values []string
+	num    float64
+}
+
+// Selector is a compiled selector expression. A selector is a comma separated
+// list of requirements a tuple must meet all together:
+//
+//	f             the tuple has the key f
+//	!f            the tuple has no key f
+//	f=png         the value of f is png, "==" is the same as "="
+//	f!=png        the tuple has no key f or the value is not png
+//	f in (a,b)    the value of f is one of a, b
+//	f notin (a,b) the tuple has no key f or the value is none of a, b
+//	h>500         the value of h is a number greater than 500, also >=, <, <=
+//
+// Values with whitespace or delimiters are quoted, i.e. note="a, b". The last
+// value of a repeated key is matched. An empty selector matches every tuple.
+type Selector struct {
+	expr string
+	reqs []requirement
+}
+
+// CompileSelector parses the selector expression. It returns SelectorError
+// when the expression is invalid.
+func CompileSelector(expr string) (*Selector, error) {
+	p := selectorParser{s: expr}
+
+	reqs, err := p.requirements()
+	if err != nil {
+		return nil, &SelectorError{Expr: expr, Pos: p.i, err: err}
+	}
+
+	return &Selector{expr: expr, reqs: reqs}, nil
+}
+
+// MustCompileSelector is like CompileSelector but panics if the expression is
+// invalid. It simplifies initialization of global selectors.
+func MustCompileSelector(expr string) *Selector {
+	s, err := CompileSelector(expr)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// String returns the source expression of the selector.
+func (s *Selector) String() string {
+	return s.expr
+}
+
+// Match reports whether the tuple meets the selector. It can be used as
+// a Document predicate, i.e. doc.Find(s.Match).
+func (s *Selector) Match(t Tuple) bool {
+	for _, req := range s.reqs {
+		if !req.match(t) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Select returns an iterator over the tuples read from r that match the
+// selector. The iteration stops after the first error.
+func (s *Selector) Select(r io.Reader, opts ...Option) iter.Seq2[Tuple, error] {
+	return func(yield func(Tuple, error) bool) {
+		tr, err := NewReader(r, opts...)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for flds, err := range tr.Fields() {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if t := Tuple(flds); s.Match(t) && !yield(t, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Select returns an iterator over the tuples read from r that match the
+// selector expression, see Selector. An invalid expression is returned as the
+// only iteration error. Compile the selector once with CompileSelector to
+// reuse it.
+//
+// Usage:
+//
+//	for t, err := range tuples.Select(r, "f in (jpeg,png),h>500") {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(t)
+//	}
+func Select(r io.Reader, expr string, opts ...Option) iter.Seq2[Tuple, error] {
+	s, err := CompileSelector(expr)
+	if err != nil {
+		return func(yield func(Tuple, error) bool) {
+			yield(nil, err)
+		}
+	}
+
+	return s.Select(r, opts...)
+}
+
+func (req requirement) match(t Tuple) bool {
+	v, ok := t.Lookup(req.key)
+
+	switch req.op {
+	case selectExists:
+		return ok
+	case selectNotExists:
+		return !ok
+	case selectEqual, selectIn:
+		return ok && req.in(v)
+	case selectNotEqual, selectNotIn:
+		return !ok || !req.in(v)
+	default:
+		return ok && req.compare(v)
+	}
+}
+
+// compare reports whether v is a number that meets the numeric comparison of
+// the requirement.
+func (req requirement) compare(v string) bool {
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return false
+	}
+
+	switch req.op {
+	case selectGreater:
+		return n > req.num
+	case selectGreaterEqual:
+		return n >= req.num
+	case selectLess:
+		return n < req.num
+	default:
+		return n <= req.num
+	}
+}
+
+func (req requirement) in(v string) bool {
+	for _, val := range req.values {
+		if v == val {
+			return true
+		}
+	}
+
+	return false
+}
+
+type selectorParser struct {
+	s string
+	i int
+}
+
+func (p *selectorParser) requirements() ([]requirement, error) {
+	var reqs []requirement
+
+	if p.skipSpaces(); p.eof() {
+		return reqs, nil
+	}
+
+	for {
+		req, err := p.requirement()
+		if err != nil {
+			return nil, err
+		}
+
+		reqs = append(reqs, req)
+
+		if p.skipSpaces(); p.eof() {
+			return reqs, nil
+		}
+
+		if !p.consume(",") {
+			return nil, errSelectorUnknown
+		}
+	}
+}
+
+func (p *selectorParser) requirement() (requirement, error) {
+	p.skipSpaces()
+
+	if p.consume("!") {
+		key, err := p.key()
+		return requirement{key: key, op: selectNotExists}, err
+	}
+
+	key, err := p.key()
+	if err != nil {
+		return requirement{}, err
+	}
+
+	req := requirement{key: key}
+
+	if p.skipSpaces(); p.eof() || p.peek() == ',' {
+		req.op = selectExists
+		return req, nil
+	}
+
+	var ok bool
+	if req.op, ok = p.operator(); !ok {
+		return req, errSelectorUnknown
+	}
+
+	if req.op == selectIn || req.op == selectNotIn {
+		req.values, err = p.list()
+		return req, err
+	}
+
+	p.skipSpaces()
+
+	start := p.i
+
+	v, err := p.value()
+	if err != nil {
+		return req, err
+	}
+
+	req.values = []string{v}
+
+	if req.op != selectEqual && req.op != selectNotEqual {
+		if req.num, err = strconv.ParseFloat(v, 64); err != nil {
+			p.i = start
+			return req, errSelectorNumber
+		}
+	}
+
+	return req, nil
+}
+
+// operator parses the operator of a requirement. Longer operators are tried
+// first, i.e. ">=" before ">".
+func (p *selectorParser) operator() (selectorOp, bool) {
+	switch {
+	case p.consume("!="):
+		return selectNotEqual, true
+	case p.consume("=="), p.consume("="):
+		return selectEqual, true
+	case p.consume(">="):
+		return selectGreaterEqual, true
+	case p.consume(">"):
+		return selectGreater, true
+	case p.consume("<="):
+		return selectLessEqual, true
+	case p.consume("<"):
+		return selectLess, true
+	case p.word("notin"):
+		return selectNotIn, true
+	case p.word("in"):
+		return selectIn, true
+	}
+
+	return selectExists, false
+}
+
+// list parses the values list, i.e. "(jpeg,png)".
+func (p *selectorParser) list() ([]string, error) {
+	if p.skipSpaces(); !p.consume("(") {
+		return nil, errSelectorList
+	}
+
+	var values []string
+
+	for {
+		p.skipSpaces()
+
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, v)
+
+		p.skipSpaces()
+
+		switch {
+		case p.consume(")"):
+			return values, nil
+		case !p.consume(","):
+			return nil, errSelectorList
+		}
+	}
+}
+
+func (p *selectorParser) key() (string, error) {
+	p.skipSpaces()
+
+	if key := p.token(); key != "" {
+		return key, nil
+	}
+
+	return "", errSelectorKey
+}
+
+// value parses a plain or a quoted value. Quotes inside a quoted value are
+// doubled.
+func (p *selectorParser) value() (string, error) {
+	if !p.consume(string(valueQuote)) {
+		if v := p.token(); v != "" {
+			return v, nil
+		}
+
+		return "", errSelectorValue
+	}
+
+	var b strings.Builder
+
+	for !p.eof() {
+		r, width := utf8.DecodeRuneInString(p.s[p.i:])
+		p.i += width
+
+		if r != valueQuote {
+			b.WriteRune(r)
+			continue
+		}
+
+		if !p.consume(string(valueQuote)) {
+			return b.String(), nil
+		}
+
+		b.WriteRune(valueQuote)
+	}
+
+	return "", errSelectorValue
+}
+
+// token parses a run of characters that are not whitespace, operators or
+// punctuation.
+func (p *selectorParser) token() string {
+	start := p.i
+
+	for !p.eof() {
+		r, width := utf8.DecodeRuneInString(p.s[p.i:])
+		if unicode.IsSpace(r) || strings.ContainsRune(`,!=<>()"`, r) {
+			break
+		}
+
+		p.i += width
+	}
+
+	return p.s[start:p.i]
+}
+
+// word consumes the keyword w when it's followed by whitespace or an opening
+// bracket.
+func (p *selectorParser) word(w string) bool {
+	rest := p.s[p.i:]
+	if !strings.HasPrefix(rest, w) {
+		return false
+	}
+
+	if r, _ := utf8.DecodeRuneInString(rest[len(w):]); !unicode.IsSpace(r) && r != '(' {
+		return false
+	}
+
+	p.i += len(w)
+
+	return true
+}
+
+func (p *selectorParser) consume(s string) bool {
+	if strings.HasPrefix(p.s[p.i:], s) {
+		p.i += len(s)
+		return true
+	}
+
+	return false
+}
+
+func (p *selectorParser) skipSpaces() {
+	for !p.eof() {
+		r, width := utf8.DecodeRuneInString(p.s[p.i:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+
+		p.i += width
+	}
+}
+
+func (p *selectorParser) peek() byte {
+	return p.s[p.i]
+}
+
+func (p *selectorParser) eof() bool {
+	return p.i >= len(p.s)
+}
@@ -0,0 +1,210 @@
+package tuples_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/antklim/tuples"
+)
+
+func TestSelectorMatch(t *testing.T) {
+	tuple := tuples.Tuple{
+		{Key: "h", Value: "700"},
+		{Key: "w", Value: "350"},
+		{Key: "f", Value: "png"},
+		{Key: "note", Value: "a, b"},
+		{Key: "f", Value: "jpeg"},
+	}
+
+	testCases := []struct {
+		expr string
+		want bool
+	}{
+		{expr: "", want: true},
+		{expr: "h", want: true},
+		{expr: "x", want: false},
+		{expr: "!x", want: true},
+		{expr: "!h", want: false},
+		{expr: "f=jpeg", want: true},
+		{expr: "f == jpeg", want: true},
+		{expr: "f=png", want: false},
+		{expr: "f!=png", want: true},
+		{expr: "x!=png", want: true},
+		{expr: "f!=jpeg", want: false},
+		{expr: "f in (gif, jpeg)", want: true},
+		{expr: "f in(gif)", want: false},
+		{expr: "x in (gif,jpeg)", want: false},
+		{expr: "f notin (gif,png)", want: true},
+		{expr: "x notin (gif)", want: true},
+		{expr: "f notin (jpeg)", want: false},
+		{expr: "h>500", want: true},
+		{expr: "h>700", want: false},
+		{expr: "h>=700", want: true},
+		{expr: "h<700.5", want: true},
+		{expr: "h<=699", want: false},
+		{expr: "f>1", want: false},
+		{expr: "x<1", want: false},
+		{expr: `note="a, b"`, want: true},
+		{expr: "h=700, w=350,!x", want: true},
+		{expr: "h=700,w=351", want: false},
+		{expr: "f in (jpeg,png),h>500", want: true},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.expr, func(t *testing.T) {
+			s, err := tuples.CompileSelector(tC.expr)
+			if err != nil {
+				t.Fatalf("unexpected CompileSelector() error: %v", err)
+			}
+
+			if got := s.Match(tuple); got != tC.want {
+				t.Errorf("Match() = %t, want %t", got, tC.want)
+			}
+		})
+	}
+}
+
+func TestCompileSelectorErrors(t *testing.T) {
+	testCases := []struct {
+		expr string
+		err  error
+	}{
+		{
+			expr: "h=",
+			err:  errors.New(`tuples: invalid selector "h=" at 2: value expected`),
+		},
+		{
+			expr: "h>big",
+			err:  errors.New(`tuples: invalid selector "h>big" at 2: number expected`),
+		},
+		{
+			expr: "f in jpeg",
+			err:  errors.New(`tuples: invalid selector "f in jpeg" at 5: values list expected`),
+		},
+		{
+			expr: "f in (jpeg png)",
+			err:  errors.New(`tuples: invalid selector "f in (jpeg png)" at 11: values list expected`),
+		},
+		{
+			expr: "h=1,",
+			err:  errors.New(`tuples: invalid selector "h=1," at 4: key expected`),
+		},
+		{
+			expr: "!",
+			err:  errors.New(`tuples: invalid selector "!" at 1: key expected`),
+		},
+		{
+			expr: "h ~ 1",
+			err:  errors.New(`tuples: invalid selector "h ~ 1" at 2: unexpected character`),
+		},
+		{
+			expr: `note="a`,
+			err:  errors.New(`tuples: invalid selector "note=\"a" at 7: value expected`),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.expr, func(t *testing.T) {
+			_, err := tuples.CompileSelector(tC.expr)
+			if err == nil || err.Error() != tC.err.Error() {
+				t.Errorf("CompileSelector() error = %v, want %v", err, tC.err)
+			}
+
+			var e *tuples.SelectorError
+			if !errors.As(err, &e) {
+				t.Errorf("CompileSelector() error is not a SelectorError")
+			}
+		})
+	}
+}
+
+func TestMustCompileSelector(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("MustCompileSelector() did not panic")
+		}
+	}()
+
+	tuples.MustCompileSelector("h>")
+}
+
+func TestSelect(t *testing.T) {
+	in := "h=700,w=350,f=jpeg h=900,w=450,f=png h=400,f=png deprecated,h=1000,f=gif"
+
+	var got []tuples.Tuple
+	for tuple, err := range tuples.Select(strings.NewReader(in), "f in (jpeg,png),h>500", tuples.WithBareKeys()) {
+		if err != nil {
+			t.Fatalf("unexpected Select() error: %v", err)
+		}
+
+		got = append(got, tuple)
+	}
+
+	expected := []tuples.Tuple{
+		{{Key: "h", Value: "700"}, {Key: "w", Value: "350"}, {Key: "f", Value: "jpeg"}},
+		{{Key: "h", Value: "900"}, {Key: "w", Value: "450"}, {Key: "f", Value: "png"}},
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Select() output:\ngot  %v\nwant %v", got, expected)
+	}
+
+	s := tuples.MustCompileSelector("!deprecated")
+	for _, r := range []string{in, "h=1 deprecated"} {
+		n := 0
+		for _, err := range s.Select(strings.NewReader(r), tuples.WithBareKeys()) {
+			if err != nil {
+				t.Fatalf("unexpected Select() error: %v", err)
+			}
+			n++
+		}
+
+		if want := strings.Count(r, " ") + 1 - strings.Count(r, "deprecated"); n != want {
+			t.Errorf("Select(%q) tuples = %d, want %d", r, n, want)
+		}
+	}
+}
+
+func TestSelectErrors(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		expr string
+		opts []tuples.Option
+		err  error
+	}{
+		{
+			desc: "invalid selector",
+			in:   "h=700",
+			expr: "h>",
+			err:  errors.New(`tuples: invalid selector "h>" at 2: value expected`),
+		},
+		{
+			desc: "invalid tuple",
+			in:   "h=700 h",
+			expr: "h",
+			err:  errors.New("tuples: scan failed: tuple #2 invalid field #1"),
+		},
+		{
+			desc: "invalid options",
+			in:   "h=700",
+			expr: "h",
+			opts: []tuples.Option{tuples.WithFieldsDelimiter('=')},
+			err:  errors.New("tuples: invalid delimiters: fields and key-value delimiters are equal"),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			var errs []error
+			for _, err := range tuples.Select(strings.NewReader(tC.in), tC.expr, tC.opts...) {
+				if err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			if len(errs) != 1 || errs[0].Error() != tC.err.Error() {
+				t.Errorf("Select() errors = %v, want [%v]", errs, tC.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,524 @@
+package tuples
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxKind describes the kind of a syntax tree node.
+type SyntaxKind int
+
+const (
+	// SyntaxSpace is whitespace between tuples.
+	SyntaxSpace SyntaxKind = iota
+	// SyntaxTuple is a tuple, its children are fields and fields delimiters.
+	// Empty fields, i.e. the second one in "700,,jpeg", have no node, only
+	// their delimiters.
+	SyntaxTuple
+	// SyntaxHeader is a header tuple, i.e. "@h,w,f", its children are the
+	// header mark, keys and fields delimiters.
+	SyntaxHeader
+	// SyntaxField is a tuple field, its children are the key, the key-value
+	// delimiter and the value. A bare key has no delimiter and value,
+	// a positional field has only a value.
+	SyntaxField
+	// SyntaxHeaderMark is the mark of a header tuple, i.e. "@".
+	SyntaxHeaderMark
+	// SyntaxKey is a field key.
+	SyntaxKey
+	// SyntaxKeyValueDelimiter is a key-value delimiter.
+	SyntaxKeyValueDelimiter
+	// SyntaxFieldsDelimiter is a fields delimiter.
+	SyntaxFieldsDelimiter
+	// SyntaxValue is a field value as it appears in the source, i.e. with
+	// quotes or brackets of a nested tuple. A nested tuple or list is one
+	// value leaf, its fields and items have no nodes and spans.
+	SyntaxValue
+)
+
+// Span is a byte range [Start, End) of the source.
+type Span struct {
+	Start int
+	End   int
+}
+
+// SyntaxNode is a node of the syntax tree. Leaves, i.e. spaces, keys,
+// delimiters and values, hold the text. Tuples and fields hold the children.
+type SyntaxNode struct {
+	Kind     SyntaxKind
+	Span     Span // nodes added by edits have zero span
+	Text     string
+	Children []*SyntaxNode
+}
+
+// SyntaxTree is a lossless concrete syntax tree of a tuples string. It keeps
+// the whitespace, delimiters and quoting of the source, so printing an
+// unmodified tree gives back the source. Edits change only the edited nodes.
+type SyntaxTree struct {
+	Nodes  []*SyntaxNode
+	opts   options
+	header []string // keys declared by the header tuple
+}
+
+// ParseSyntax parses the tuples string data into a syntax tree. It returns
+// the same errors as the reader for invalid tuples.
+func ParseSyntax(data []byte, opts ...Option) (*SyntaxTree, error) {
+	o := newOptions(opts)
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+
+	sp := syntaxParser{opts: o.scannerOpts()}
+	t := &SyntaxTree{opts: o}
+
+	for off, pos := 0, 0; off < len(data); {
+		start := off + spaceLen(data[off:])
+		if start > off {
+			t.Nodes = append(t.Nodes, &SyntaxNode{Kind: SyntaxSpace, Span: Span{off, start}, Text: string(data[off:start])})
+		}
+
+		_, token, _ := sp.opts.scanTuples(data[start:], true)
+		if token == nil {
+			break
+		}
+
+		pos++
+
+		n, err := sp.tuple(string(token), start, pos == 1)
+		if err != nil {
+			return nil, &ScannerError{fmt.Errorf("tuple #%d %w", pos, err)}
+		}
+
+		t.Nodes = append(t.Nodes, n)
+		off = start + len(token)
+	}
+
+	t.header = sp.header
+
+	return t, nil
+}
+
+// syntaxParser builds syntax nodes of tuples.
+type syntaxParser struct {
+	opts   scannerOptions
+	header []string // keys declared by the header tuple
+}
+
+// tuple validates the tuple s the same way as the scanner and builds its
+// syntax node. base is the offset of the tuple in the source.
+func (sp *syntaxParser) tuple(s string, base int, first bool) (*SyntaxNode, error) {
+	so := sp.opts
+	fdLen := utf8.RuneLen(so.fd)
+
+	if so.header && first {
+		keys, err := parseHeader(s, so)
+		if err != nil {
+			return nil, err
+		}
+
+		sp.header = keys
+
+		n := &SyntaxNode{Kind: SyntaxHeader, Span: Span{base, base + len(s)}}
+
+		i := 0
+		n.Children = append(n.Children, leaf(SyntaxHeaderMark, s, base, &i, utf8.RuneLen(headerMark)))
+
+		for k, key := range keys {
+			if k > 0 {
+				n.Children = append(n.Children, leaf(SyntaxFieldsDelimiter, s, base, &i, fdLen))
+			}
+
+			n.Children = append(n.Children, leaf(SyntaxKey, s, base, &i, len(key)))
+		}
+
+		return n, nil
+	}
+
+	flds, fieldNum, err := parseTuple(s, so)
+	if err == nil && so.header {
+		fieldNum, err = keyByHeader(flds, sp.header)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid field #%d", fieldNum)
+	}
+
+	n := &SyntaxNode{Kind: SyntaxTuple, Span: Span{base, base + len(s)}}
+
+	for i := 0; ; {
+		if f := sp.field(s, base, &i); f != nil {
+			n.Children = append(n.Children, f)
+		}
+
+		if i >= len(s) {
+			break
+		}
+
+		n.Children = append(n.Children, leaf(SyntaxFieldsDelimiter, s, base, &i, fdLen))
+	}
+
+	return n, nil
+}
+
+// field builds the node of the field of the valid tuple text s starting at
+// *i and moves *i past it. It returns nil for an empty field.
+func (sp *syntaxParser) field(s string, base int, i *int) *SyntaxNode {
+	so := sp.opts
+	if *i >= len(s) || strings.HasPrefix(s[*i:], string(so.fd)) {
+		return nil
+	}
+
+	f := &SyntaxNode{Kind: SyntaxField, Span: Span{Start: base + *i}}
+
+	if !so.positional && !so.header {
+		keyLen := strings.IndexFunc(s[*i:], func(r rune) bool { return r == so.kvd || r == so.fd })
+		if keyLen < 0 {
+			keyLen = len(s) - *i
+		}
+
+		f.Children = append(f.Children, leaf(SyntaxKey, s, base, i, keyLen))
+
+		if !strings.HasPrefix(s[*i:], string(so.kvd)) {
+			f.Span.End = base + *i
+			return f
+		}
+
+		f.Children = append(f.Children, leaf(SyntaxKeyValueDelimiter, s, base, i, utf8.RuneLen(so.kvd)))
+	}
+
+	f.Children = append(f.Children, leaf(SyntaxValue, s, base, i, so.valueLen(s[*i:])))
+	f.Span.End = base + *i
+
+	return f
+}
+
+// leaf returns a leaf node of the size bytes of s starting at *i and moves *i
+// past it.
+func leaf(kind SyntaxKind, s string, base int, i *int, size int) *SyntaxNode {
+	n := &SyntaxNode{Kind: kind, Span: Span{base + *i, base + *i + size}, Text: s[*i : *i+size]}
+	*i += size
+
+	return n
+}
+
+// valueLen returns the length of the value at the start of the valid tuple
+// text s. Brackets and quotes of nested values are matched.
+func (so scannerOptions) valueLen(s string) int {
+	depth, quoted := 0, false
+	prev := rune(-1)
+
+	for width, i := 0, 0; i < len(s); i += width {
+		var r rune
+		r, width = utf8.DecodeRuneInString(s[i:])
+
+		switch {
+		case quoted:
+			quoted, width = inQuotes(s, i, r, width)
+		case r == valueQuote && (i == 0 || so.valueStart(prev)):
+			quoted = true
+		case r == so.fd && depth == 0:
+			return i
+		default:
+			depth = nestDepth(r, depth, i == 0)
+		}
+
+		prev = r
+	}
+
+	return len(s)
+}
+
+// inQuotes reports whether a quoted value goes on after the rune r of the
+// width at s[i]. A doubled quote is skipped, so the width of both quotes is
+// returned.
+func inQuotes(s string, i int, r rune, width int) (bool, int) {
+	if r != valueQuote {
+		return true, width
+	}
+
+	if i+width < len(s) && s[i+width] == valueQuote {
+		return true, width + 1
+	}
+
+	return false, width
+}
+
+// nestDepth returns the brackets depth after the rune r. Brackets open
+// a nested value only at the value start or inside another nested value.
+func nestDepth(r rune, depth int, start bool) int {
+	switch {
+	case (r == tupleOpen || r == listOpen) && (start || depth > 0):
+		return depth + 1
+	case (r == tupleClose || r == listClose) && depth > 0:
+		return depth - 1
+	}
+
+	return depth
+}
+
+// Bytes returns the text of the tree. An unmodified tree gives back the
+// parsed source byte for byte.
+func (t *SyntaxTree) Bytes() []byte {
+	return []byte(t.String())
+}
+
+// String returns the text of the tree.
+func (t *SyntaxTree) String() string {
+	var b strings.Builder
+	for _, n := range t.Nodes {
+		n.write(&b)
+	}
+
+	return b.String()
+}
+
+// Tuples returns the tuple nodes of the tree, the header tuple excluded.
+func (t *SyntaxTree) Tuples() []*SyntaxNode {
+	var tuples []*SyntaxNode
+	for _, n := range t.Nodes {
+		if n.Kind == SyntaxTuple {
+			tuples = append(tuples, n)
+		}
+	}
+
+	return tuples
+}
+
+// SetValue sets the value of the last field with the key of the tuple node.
+// Only the value node is changed, a bare key gets the key-value delimiter and
+// the value. When the tuple has no such field, the field is appended. The
+// value is quoted when it's needed, nested tuples and lists are set as is, as
+// one value leaf. A key that cannot be read back, i.e. "" or "a b", causes
+// WriterError.
+//
+// Fields of header and positional tuples are found by position: the index
+// of the key in the header, or the key itself, i.e. "2". Fields delimiters
+// are added up to the position when needed. A key that is not in the header
+// or is not a position causes WriterError.
+func (t *SyntaxTree) SetValue(tuple *SyntaxNode, key, value string) error {
+	if !t.opts.validKey(key) {
+		return &WriterError{fmt.Errorf("%w %q", errInvalidKey, key)}
+	}
+
+	so := t.opts.scannerOpts()
+	if !isStructured(value, so) {
+		value = t.opts.quoteValue(value)
+	}
+
+	val := &SyntaxNode{Kind: SyntaxValue, Text: value}
+
+	if so.positional || so.header {
+		pos, err := t.position(key)
+		if err != nil {
+			return err
+		}
+
+		t.setPositional(tuple, pos, val)
+
+		return nil
+	}
+
+	if f := tuple.Field(key); f != nil {
+		if v := f.child(SyntaxValue); v != nil {
+			v.Text = value
+			return nil
+		}
+
+		f.Children = append(f.Children, t.leaf(SyntaxKeyValueDelimiter, string(so.kvd)), val)
+
+		return nil
+	}
+
+	if len(tuple.Children) > 0 {
+		tuple.Children = append(tuple.Children, t.leaf(SyntaxFieldsDelimiter, string(so.fd)))
+	}
+
+	tuple.Children = append(tuple.Children, &SyntaxNode{
+		Kind:     SyntaxField,
+		Children: []*SyntaxNode{t.leaf(SyntaxKey, key), t.leaf(SyntaxKeyValueDelimiter, string(so.kvd)), val},
+	})
+
+	return nil
+}
+
+// position returns the position of the key in header and positional tuples.
+func (t *SyntaxTree) position(key string) (int, error) {
+	if t.opts.header {
+		if pos := slices.Index(t.header, key); pos >= 0 {
+			return pos, nil
+		}
+
+		return 0, &WriterError{fmt.Errorf("%w %q", errHeaderKey, key)}
+	}
+
+	pos, err := strconv.Atoi(key)
+	if err != nil || pos < 0 {
+		return 0, &WriterError{fmt.Errorf("%w %q", errInvalidKey, key)}
+	}
+
+	return pos, nil
+}
+
+// setPositional sets the value node of the field at the position pos of the
+// tuple node. An empty field is filled in place, fields delimiters are
+// appended when the tuple is shorter.
+func (t *SyntaxTree) setPositional(tuple *SyntaxNode, pos int, val *SyntaxNode) {
+	p, at := 0, 0
+
+	for i, c := range tuple.Children {
+		if c.Kind == SyntaxFieldsDelimiter {
+			if p++; p == pos {
+				at = i + 1
+			}
+
+			continue
+		}
+
+		if p == pos {
+			c.Children = []*SyntaxNode{val}
+			return
+		}
+	}
+
+	if p < pos {
+		for ; p < pos; p++ {
+			tuple.Children = append(tuple.Children, t.leaf(SyntaxFieldsDelimiter, string(t.opts.fieldsDelimiter)))
+		}
+
+		at = len(tuple.Children)
+	}
+
+	tuple.Children = slices.Insert(tuple.Children, at, &SyntaxNode{Kind: SyntaxField, Children: []*SyntaxNode{val}})
+}
+
+// DeleteField deletes the fields with the key from the tuple node along with
+// their fields delimiters. It returns the number of deleted fields.
+//
+// A field of header and positional tuples is found by position, see SetValue.
+// Its delimiters are kept, so the field becomes empty and other fields keep
+// their positions.
+func (t *SyntaxTree) DeleteField(tuple *SyntaxNode, key string) int {
+	if t.opts.positional || t.opts.header {
+		return t.deletePositional(tuple, key)
+	}
+
+	var (
+		children []*SyntaxNode
+		deleted  int
+		dropNext bool // the delimiter after the deleted first field
+	)
+
+	for _, n := range tuple.Children {
+		switch {
+		case n.Kind == SyntaxField && n.Key() == key:
+			deleted++
+
+			if len(children) > 0 {
+				children = children[:len(children)-1]
+			} else {
+				dropNext = true
+			}
+		case dropNext && n.Kind == SyntaxFieldsDelimiter:
+			dropNext = false
+		default:
+			children = append(children, n)
+		}
+	}
+
+	tuple.Children = children
+
+	return deleted
+}
+
+func (t *SyntaxTree) deletePositional(tuple *SyntaxNode, key string) int {
+	pos, err := t.position(key)
+	if err != nil {
+		return 0
+	}
+
+	p := 0
+	for i, c := range tuple.Children {
+		switch {
+		case c.Kind == SyntaxFieldsDelimiter:
+			p++
+		case p == pos:
+			tuple.Children = slices.Delete(tuple.Children, i, i+1)
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func (t *SyntaxTree) leaf(kind SyntaxKind, text string) *SyntaxNode {
+	return &SyntaxNode{Kind: kind, Text: text}
+}
+
+// String returns the text of the node.
+func (n *SyntaxNode) String() string {
+	var b strings.Builder
+	n.write(&b)
+
+	return b.String()
+}
+
+func (n *SyntaxNode) write(b *strings.Builder) {
+	b.WriteString(n.Text)
+
+	for _, c := range n.Children {
+		c.write(b)
+	}
+}
+
+// Field returns the last field with the key of the tuple node. It returns nil
+// when there is no such field.
+func (n *SyntaxNode) Field(key string) *SyntaxNode {
+	var f *SyntaxNode
+	for _, c := range n.Children {
+		if c.Kind == SyntaxField && c.Key() == key {
+			f = c
+		}
+	}
+
+	return f
+}
+
+// Key returns the key of the field node. Positional fields have no key.
+func (n *SyntaxNode) Key() string {
+	if k := n.child(SyntaxKey); k != nil {
+		return k.Text
+	}
+
+	return ""
+}
+
+// Value returns the value of the field node. Quoted values returned unquoted,
+// nested tuples and lists are returned as they appear in the source.
+func (n *SyntaxNode) Value() string {
+	v := n.child(SyntaxValue)
+	if v == nil {
+		return ""
+	}
+
+	if strings.HasPrefix(v.Text, string(valueQuote)) {
+		p := parser{s: v.Text}
+		if s, err := p.quoted(); err == nil {
+			return s
+		}
+	}
+
+	return v.Text
+}
+
+func (n *SyntaxNode) child(kind SyntaxKind) *SyntaxNode {
+	for _, c := range n.Children {
+		if c.Kind == kind {
+			return c
+		}
+	}
+
+	return nil
+}
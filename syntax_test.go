@@ -0,0 +1,357 @@
+package tuples_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/antklim/tuples"
+)
+
+var syntaxRoundTripTests = []struct {
+	desc string
+	in   string
+	opts []tuples.Option
+}{
+	{
+		desc: "empty input",
+		in:   "",
+	},
+	{
+		desc: "spaces only",
+		in:   " \n\t ",
+	},
+	{
+		desc: "tuples separated by mixed whitespace",
+		in:   "  h=700,w=350\n\n\th=900,w=450  \n",
+	},
+	{
+		desc: "quoted and nested values",
+		in:   `name="a ""b"" c",size=(w=1,h=[2,3]),tags=[a,"b c"] note=""`,
+	},
+	{
+		desc: "bare keys",
+		in:   "ro,noexec,uid=1000",
+		opts: []tuples.Option{tuples.WithBareKeys()},
+	},
+	{
+		desc: "positional tuples with empty fields",
+		in:   "700,,jpeg\n,450,",
+		opts: []tuples.Option{tuples.WithPositional()},
+	},
+	{
+		desc: "header tuples",
+		in:   "@h,w,f 700,350,jpeg\n900,450,png",
+		opts: []tuples.Option{tuples.WithHeader()},
+	},
+	{
+		desc: "custom delimiters",
+		in:   "h:700;w:350 h:900;note:\"a;b\"",
+		opts: []tuples.Option{tuples.WithFieldsDelimiter(';'), tuples.WithKeyValueDelimiter(':')},
+	},
+}
+
+func TestSyntaxRoundTrip(t *testing.T) {
+	for _, tC := range syntaxRoundTripTests {
+		t.Run(tC.desc, func(t *testing.T) {
+			tree, err := tuples.ParseSyntax([]byte(tC.in), tC.opts...)
+			if err != nil {
+				t.Fatalf("unexpected ParseSyntax() error: %v", err)
+			}
+
+			if out := string(tree.Bytes()); out != tC.in {
+				t.Errorf("Bytes() output:\ngot  %q\nwant %q", out, tC.in)
+			}
+
+			for _, n := range tree.Nodes {
+				checkSpans(t, tC.in, n)
+			}
+		})
+	}
+}
+
+// checkSpans checks that every leaf text is the source text of its span.
+func checkSpans(t *testing.T, src string, n *tuples.SyntaxNode) {
+	t.Helper()
+
+	if got := src[n.Span.Start:n.Span.End]; got != n.String() {
+		t.Errorf("node %d span %v text = %q, want %q", n.Kind, n.Span, got, n.String())
+	}
+
+	for _, c := range n.Children {
+		checkSpans(t, src, c)
+	}
+}
+
+func TestSyntaxNodes(t *testing.T) {
+	tree, err := tuples.ParseSyntax([]byte(` h=700,f="a b"`))
+	if err != nil {
+		t.Fatalf("unexpected ParseSyntax() error: %v", err)
+	}
+
+	type leaf struct {
+		Kind tuples.SyntaxKind
+		Span tuples.Span
+		Text string
+	}
+
+	var leaves []leaf
+	var walk func(n *tuples.SyntaxNode)
+	walk = func(n *tuples.SyntaxNode) {
+		if len(n.Children) == 0 {
+			leaves = append(leaves, leaf{n.Kind, n.Span, n.Text})
+		}
+
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+
+	for _, n := range tree.Nodes {
+		walk(n)
+	}
+
+	expected := []leaf{
+		{tuples.SyntaxSpace, tuples.Span{Start: 0, End: 1}, " "},
+		{tuples.SyntaxKey, tuples.Span{Start: 1, End: 2}, "h"},
+		{tuples.SyntaxKeyValueDelimiter, tuples.Span{Start: 2, End: 3}, "="},
+		{tuples.SyntaxValue, tuples.Span{Start: 3, End: 6}, "700"},
+		{tuples.SyntaxFieldsDelimiter, tuples.Span{Start: 6, End: 7}, ","},
+		{tuples.SyntaxKey, tuples.Span{Start: 7, End: 8}, "f"},
+		{tuples.SyntaxKeyValueDelimiter, tuples.Span{Start: 8, End: 9}, "="},
+		{tuples.SyntaxValue, tuples.Span{Start: 9, End: 14}, `"a b"`},
+	}
+
+	if !reflect.DeepEqual(leaves, expected) {
+		t.Errorf("leaves:\ngot  %v\nwant %v", leaves, expected)
+	}
+
+	f := tree.Tuples()[0].Field("f")
+	if f == nil {
+		t.Fatal("Field() = nil, want field")
+	}
+
+	if f.Key() != "f" || f.Value() != "a b" {
+		t.Errorf("Key(), Value() = %q, %q, want %q, %q", f.Key(), f.Value(), "f", "a b")
+	}
+}
+
+func TestSyntaxEdit(t *testing.T) {
+	in := "h=700,w=350\n\n  h=900,f=png,ro,w=450\tx=1"
+
+	tree, err := tuples.ParseSyntax([]byte(in), tuples.WithBareKeys())
+	if err != nil {
+		t.Fatalf("unexpected ParseSyntax() error: %v", err)
+	}
+
+	tuple := tree.Tuples()[1]
+	for _, kv := range [][2]string{{"f", "a b"}, {"ro", "yes"}, {"size", "(w=1)"}} {
+		if err := tree.SetValue(tuple, kv[0], kv[1]); err != nil {
+			t.Fatalf("unexpected SetValue() error: %v", err)
+		}
+	}
+
+	if n := tree.DeleteField(tuple, "h"); n != 1 {
+		t.Errorf("DeleteField() = %d, want 1", n)
+	}
+
+	if n := tree.DeleteField(tree.Tuples()[0], "w"); n != 1 {
+		t.Errorf("DeleteField() = %d, want 1", n)
+	}
+
+	if n := tree.DeleteField(tree.Tuples()[2], "y"); n != 0 {
+		t.Errorf("DeleteField() = %d, want 0", n)
+	}
+
+	want := "h=700\n\n  f=\"a b\",ro=yes,w=450,size=(w=1)\tx=1"
+	if out := tree.String(); out != want {
+		t.Errorf("String() output:\ngot  %q\nwant %q", out, want)
+	}
+}
+
+func TestSyntaxEditPositions(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		in     string
+		opts   []tuples.Option
+		set    [][2]string
+		delete []string
+		out    string
+	}{
+		{
+			desc:   "positional",
+			in:     "700,,jpeg\n,450",
+			opts:   []tuples.Option{tuples.WithPositional()},
+			set:    [][2]string{{"1", "350"}, {"0", "(h=1)"}},
+			delete: []string{"2", "x"},
+			out:    "(h=1),350,\n,450",
+		},
+		{
+			desc: "positional past the end",
+			in:   "700 ,450,",
+			opts: []tuples.Option{tuples.WithPositional()},
+			set:  [][2]string{{"3", "a b"}},
+			out:  `700,,,"a b" ,450,`,
+		},
+		{
+			desc:   "header",
+			in:     "@h,w,f 700,350\n900,,png",
+			opts:   []tuples.Option{tuples.WithHeader()},
+			set:    [][2]string{{"f", "gif"}, {"w", "1"}},
+			delete: []string{"h"},
+			out:    "@h,w,f ,1,gif\n900,,png",
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			tree, err := tuples.ParseSyntax([]byte(tC.in), tC.opts...)
+			if err != nil {
+				t.Fatalf("unexpected ParseSyntax() error: %v", err)
+			}
+
+			tuple := tree.Tuples()[0]
+			for _, kv := range tC.set {
+				if err := tree.SetValue(tuple, kv[0], kv[1]); err != nil {
+					t.Fatalf("unexpected SetValue() error: %v", err)
+				}
+			}
+
+			for _, key := range tC.delete {
+				tree.DeleteField(tuple, key)
+			}
+
+			if out := tree.String(); out != tC.out {
+				t.Errorf("String() output:\ngot  %q\nwant %q", out, tC.out)
+			}
+		})
+	}
+}
+
+func TestSyntaxEditPositionsFails(t *testing.T) {
+	tree, err := tuples.ParseSyntax([]byte("@h,w 700,350"), tuples.WithHeader())
+	if err != nil {
+		t.Fatalf("unexpected ParseSyntax() error: %v", err)
+	}
+
+	err = tree.SetValue(tree.Tuples()[0], "f", "png")
+
+	expected := errors.New(`tuples: write failed: key not in header "f"`)
+	if !eqErrors(err, expected) {
+		t.Errorf("SetValue() error mismatch:\ngot  %v\nwant %v", err, expected)
+	}
+
+	if out := tree.String(); out != "@h,w 700,350" {
+		t.Errorf("String() output:\ngot  %q\nwant %q", out, "@h,w 700,350")
+	}
+}
+
+func TestSyntaxSetValueInvalidKey(t *testing.T) {
+	in := "h=700,w=350"
+
+	for _, key := range []string{"", "a b", "a=b", "a,b", "(a)", `"a"`} {
+		tree, err := tuples.ParseSyntax([]byte(in))
+		if err != nil {
+			t.Fatalf("unexpected ParseSyntax() error: %v", err)
+		}
+
+		err = tree.SetValue(tree.Tuples()[0], key, "1")
+
+		expected := fmt.Errorf("tuples: write failed: invalid key %q", key)
+		if !eqErrors(err, expected) {
+			t.Errorf("SetValue(%q) error mismatch:\ngot  %v\nwant %v", key, err, expected)
+		}
+
+		if out := tree.String(); out != in {
+			t.Errorf("String() output:\ngot  %q\nwant %q", out, in)
+		}
+	}
+}
+
+func TestSyntaxEmptyFields(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		opts []tuples.Option
+		kind []tuples.SyntaxKind
+	}{
+		{
+			desc: "keyed",
+			in:   ",a=1,,b=2,",
+			kind: []tuples.SyntaxKind{
+				tuples.SyntaxFieldsDelimiter, tuples.SyntaxField, tuples.SyntaxFieldsDelimiter,
+				tuples.SyntaxFieldsDelimiter, tuples.SyntaxField, tuples.SyntaxFieldsDelimiter,
+			},
+		},
+		{
+			desc: "positional",
+			in:   ",700,",
+			opts: []tuples.Option{tuples.WithPositional()},
+			kind: []tuples.SyntaxKind{tuples.SyntaxFieldsDelimiter, tuples.SyntaxField, tuples.SyntaxFieldsDelimiter},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			tree, err := tuples.ParseSyntax([]byte(tC.in), tC.opts...)
+			if err != nil {
+				t.Fatalf("unexpected ParseSyntax() error: %v", err)
+			}
+
+			var kind []tuples.SyntaxKind
+			for _, c := range tree.Tuples()[0].Children {
+				kind = append(kind, c.Kind)
+			}
+
+			if !reflect.DeepEqual(kind, tC.kind) {
+				t.Errorf("children kinds:\ngot  %v\nwant %v", kind, tC.kind)
+			}
+
+			if out := tree.String(); out != tC.in {
+				t.Errorf("String() output:\ngot  %q\nwant %q", out, tC.in)
+			}
+		})
+	}
+}
+
+func TestSyntaxErrors(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		opts []tuples.Option
+		err  error
+	}{
+		{
+			desc: "invalid field",
+			in:   "h=700 w=(350",
+			err:  errors.New("tuples: scan failed: tuple #2 invalid field #1"),
+		},
+		{
+			desc: "invalid header",
+			in:   "h,w 700,350",
+			opts: []tuples.Option{tuples.WithHeader()},
+			err:  errors.New("tuples: scan failed: tuple #1 invalid header"),
+		},
+		{
+			desc: "too many header values",
+			in:   "@h,w 700,350,jpeg",
+			opts: []tuples.Option{tuples.WithHeader()},
+			err:  errors.New("tuples: scan failed: tuple #2 invalid field #3"),
+		},
+		{
+			desc: "invalid delimiter",
+			in:   "h=700",
+			opts: []tuples.Option{tuples.WithFieldsDelimiter('"')},
+			err:  errors.New("tuples: invalid delimiters: invalid fields delimiter"),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			_, err := tuples.ParseSyntax([]byte(tC.in), tC.opts...)
+			if err == nil || err.Error() != tC.err.Error() {
+				t.Errorf("ParseSyntax() error = %v, want %v", err, tC.err)
+			}
+		})
+	}
+}
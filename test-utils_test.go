@@ -11,3 +11,7 @@ func eqErrors(a, b error) bool {
 
 	return a.Error() == b.Error()
 }
+
+func ptrTo[T any](v T) *T {
+	return &v
+}
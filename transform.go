@@ -0,0 +1,130 @@
+package tuples
+
+import (
+	"io"
+	"slices"
+)
+
+// Transform reads tuples from src, passes every tuple to fn and writes the
+// returned tuples to dst. A tuple is dropped when fn returns false or an empty
+// tuple. Options are used for both reading and writing, so the output has the
+// same delimiters as the input. Tuples are processed one at a time, in the
+// input order, without holding the whole input in memory.
+//
+// Usage:
+//
+//	err := tuples.Transform(os.Stdout, os.Stdin, tuples.Chain(
+//		tuples.RenameKeys(map[string]string{"f": "format"}),
+//		tuples.DropKeys("note"),
+//	))
+func Transform(dst io.Writer, src io.Reader, fn func(Tuple) (Tuple, bool), opts ...Option) error {
+	r, err := NewReader(src, opts...)
+	if err != nil {
+		return err
+	}
+
+	w, err := NewWriter(dst, opts...)
+	if err != nil {
+		return err
+	}
+
+	for flds, err := range r.Fields() {
+		if err != nil {
+			return err
+		}
+
+		t, ok := fn(Tuple(flds))
+		if !ok || len(t) == 0 {
+			continue
+		}
+
+		if err := w.Write(t); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+
+	return w.Error()
+}
+
+// Chain returns a transform that applies fns in order. It drops a tuple as
+// soon as one of fns drops it.
+func Chain(fns ...func(Tuple) (Tuple, bool)) func(Tuple) (Tuple, bool) {
+	return func(t Tuple) (Tuple, bool) {
+		for _, fn := range fns {
+			var ok bool
+			if t, ok = fn(t); !ok {
+				return nil, false
+			}
+		}
+
+		return t, true
+	}
+}
+
+// Filter returns a transform that keeps only the tuples that match the
+// predicate, i.e. Filter(selector.Match).
+func Filter(match func(Tuple) bool) func(Tuple) (Tuple, bool) {
+	return func(t Tuple) (Tuple, bool) {
+		return t, match(t)
+	}
+}
+
+// KeepKeys returns a transform that keeps only the fields with the keys. The
+// fields stay in the tuple order.
+func KeepKeys(keys ...string) func(Tuple) (Tuple, bool) {
+	return func(t Tuple) (Tuple, bool) {
+		return filterFields(t, func(f Field) bool { return slices.Contains(keys, f.Key) }), true
+	}
+}
+
+// DropKeys returns a transform that removes the fields with the keys.
+func DropKeys(keys ...string) func(Tuple) (Tuple, bool) {
+	return func(t Tuple) (Tuple, bool) {
+		return filterFields(t, func(f Field) bool { return !slices.Contains(keys, f.Key) }), true
+	}
+}
+
+// RenameKeys returns a transform that renames the fields keys by the names
+// map of old to new keys. Keys missing in the map stay as is.
+func RenameKeys(names map[string]string) func(Tuple) (Tuple, bool) {
+	return func(t Tuple) (Tuple, bool) {
+		renamed := make(Tuple, len(t))
+		for i, f := range t {
+			if name, ok := names[f.Key]; ok {
+				f.Key = name
+			}
+
+			renamed[i] = f
+		}
+
+		return renamed, true
+	}
+}
+
+// Defaults returns a transform that appends the fields which keys are missing
+// in the tuple. Existing values are never changed.
+func Defaults(fields ...Field) func(Tuple) (Tuple, bool) {
+	return func(t Tuple) (Tuple, bool) {
+		out := slices.Clone(t)
+		for _, f := range fields {
+			if _, ok := out.Lookup(f.Key); !ok {
+				out = append(out, f)
+			}
+		}
+
+		return out, true
+	}
+}
+
+func filterFields(t Tuple, keep func(Field) bool) Tuple {
+	out := make(Tuple, 0, len(t))
+	for _, f := range t {
+		if keep(f) {
+			out = append(out, f)
+		}
+	}
+
+	return out
+}
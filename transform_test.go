@@ -0,0 +1,164 @@
+package tuples_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/antklim/tuples"
+)
+
+func TestTransform(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		fn   func(tuples.Tuple) (tuples.Tuple, bool)
+		out  string
+		opts []tuples.Option
+	}{
+		{
+			desc: "identity",
+			in:   `h=700,note="a b" h=900`,
+			fn:   func(t tuples.Tuple) (tuples.Tuple, bool) { return t, true },
+			out:  `h=700,note="a b" h=900`,
+		},
+		{
+			desc: "nested values",
+			in:   `s=(h=1,w=2),l=[a,b],q="(a=1)",n="a b"`,
+			fn:   func(t tuples.Tuple) (tuples.Tuple, bool) { return t, true },
+			out:  `s=(h=1,w=2),l=[a,b],q="(a=1)",n="a b"`,
+		},
+		{
+			desc: "nested values with header",
+			in:   `@s,l 0,[a,b] (h=1),"[x]"`,
+			fn:   func(t tuples.Tuple) (tuples.Tuple, bool) { return t, true },
+			out:  `@s,l 0,[a,b] (h=1),"[x]"`,
+			opts: []tuples.Option{tuples.WithHeader()},
+		},
+		{
+			desc: "keep keys",
+			in:   "h=700,w=350,f=jpeg f=png,x=1 x=2",
+			fn:   tuples.KeepKeys("f", "h"),
+			out:  "h=700,f=jpeg f=png",
+		},
+		{
+			desc: "drop keys",
+			in:   "h=700,w=350,f=jpeg w=1",
+			fn:   tuples.DropKeys("w"),
+			out:  "h=700,f=jpeg",
+		},
+		{
+			desc: "rename keys",
+			in:   "h=700,w=350,f=jpeg",
+			fn:   tuples.RenameKeys(map[string]string{"h": "height", "w": "width"}),
+			out:  "height=700,width=350,f=jpeg",
+		},
+		{
+			desc: "defaults",
+			in:   "h=700,f=jpeg h=900",
+			fn:   tuples.Defaults(tuples.Field{Key: "f", Value: "png"}, tuples.Field{Key: "q", Value: "80"}),
+			out:  "h=700,f=jpeg,q=80 h=900,f=png,q=80",
+		},
+		{
+			desc: "chain with filter",
+			in:   "h=700,f=jpeg,note=x h=400,f=png h=900,f=gif",
+			fn: tuples.Chain(
+				tuples.Filter(tuples.MustCompileSelector("h>500").Match),
+				tuples.DropKeys("note"),
+				tuples.RenameKeys(map[string]string{"f": "format"}),
+			),
+			out: "h=700,format=jpeg h=900,format=gif",
+		},
+		{
+			desc: "custom delimiters",
+			in:   "h:700;f:jpeg h:900",
+			fn:   tuples.Defaults(tuples.Field{Key: "note", Value: "a;b"}),
+			out:  `h:700;f:jpeg;note:"a;b" h:900;note:"a;b"`,
+			opts: []tuples.Option{tuples.WithFieldsDelimiter(';'), tuples.WithKeyValueDelimiter(':')},
+		},
+		{
+			desc: "header",
+			in:   "@h,w,f 700,350,jpeg 900,450,png",
+			fn:   tuples.KeepKeys("f", "h"),
+			out:  "@h,f 700,jpeg 900,png",
+			opts: []tuples.Option{tuples.WithHeader()},
+		},
+		{
+			desc: "empty input",
+			in:   "",
+			fn:   tuples.KeepKeys("h"),
+			out:  "",
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := tuples.Transform(&b, strings.NewReader(tC.in), tC.fn, tC.opts...); err != nil {
+				t.Fatalf("unexpected Transform() error: %v", err)
+			}
+
+			if out := b.String(); out != tC.out {
+				t.Errorf("Transform() output:\ngot  %s\nwant %s", out, tC.out)
+			}
+		})
+	}
+}
+
+func TestTransformDoesNotChangeInput(t *testing.T) {
+	in := tuples.Tuple{{Key: "h", Value: "700"}, {Key: "w", Value: "350"}}
+	fn := tuples.Chain(tuples.RenameKeys(map[string]string{"h": "height"}), tuples.Defaults(tuples.Field{Key: "f", Value: "png"}))
+
+	out, ok := fn(in)
+	if !ok {
+		t.Fatal("transform dropped the tuple")
+	}
+
+	if got, want := out.String(), "height=700,w=350,f=png"; got != want {
+		t.Errorf("transform output = %s, want %s", got, want)
+	}
+
+	if got, want := in.String(), "h=700,w=350"; got != want {
+		t.Errorf("transform input changed to %s, want %s", got, want)
+	}
+}
+
+func TestTransformErrors(t *testing.T) {
+	testCases := []struct {
+		desc string
+		in   string
+		fn   func(tuples.Tuple) (tuples.Tuple, bool)
+		opts []tuples.Option
+		err  error
+	}{
+		{
+			desc: "invalid input",
+			in:   "h=700 w=(",
+			fn:   tuples.DropKeys("x"),
+			err:  errors.New("tuples: scan failed: tuple #2 invalid field #1"),
+		},
+		{
+			desc: "invalid key",
+			in:   "h=700",
+			fn:   tuples.RenameKeys(map[string]string{"h": "a b"}),
+			err:  errors.New(`tuples: write failed: invalid key "a b"`),
+		},
+		{
+			desc: "invalid options",
+			in:   "h=700",
+			fn:   tuples.DropKeys("x"),
+			opts: []tuples.Option{tuples.WithHeader(), tuples.WithPositional()},
+			err:  errors.New("tuples: invalid delimiters: header and positional tuples cannot be combined"),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			var b bytes.Buffer
+
+			err := tuples.Transform(&b, strings.NewReader(tC.in), tC.fn, tC.opts...)
+			if !eqErrors(err, tC.err) {
+				t.Errorf("Transform() error mismatch:\ngot  %v\nwant %v", err, tC.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package tuples
+
+import "reflect"
+
+// Field is a key-value pair of a tuple. Bare keys have an empty value. Values
+// of nested tuples and lists keep their text, i.e. "(h=700,w=350)".
+//
+// Quoted marks a value that reads as a nested tuple or list as a plain string,
+// i.e. `note="(a=1)"` is read as Field{Key: "note", Value: "(a=1)", Quoted:
+// true}. Such a value is written in quotes. Other values are quoted when
+// needed regardless of the mark.
+type Field struct {
+	Key    string
+	Value  string
+	Quoted bool
+}
+
+// nodeField returns the scanned field as a Field. A quoted value that reads as
+// a nested tuple or list is marked, so it's written back quoted.
+func nodeField(n node, opts scannerOptions) Field {
+	val := fieldValue(n)
+	return Field{Key: n.key, Value: val, Quoted: n.quoted && isStructured(val, opts)}
+}
+
+// Tuple is an ordered list of fields. Unlike a map it keeps the fields order
+// and repeated keys. It can be a decoding destination, i.e. []Tuple, and is
+// marshaled in the stored order.
+type Tuple []Field
+
+var tupleType = reflect.TypeOf(Tuple(nil))
+
+// Get returns the value of the last field with the key. It returns an empty
+// string if there is no such field.
+func (t Tuple) Get(key string) string {
+	v, _ := t.Lookup(key)
+	return v
+}
+
+// Lookup returns the value of the last field with the key and reports whether
+// the field is present.
+func (t Tuple) Lookup(key string) (string, bool) {
+	for i := len(t) - 1; i >= 0; i-- {
+		if t[i].Key == key {
+			return t[i].Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Set sets the value of the field with the key. The first field with the key
+// keeps its position and Quoted mark, so a value got with Get is written back
+// the same way, and repeated ones are removed. The field is appended if there
+// is no such field. Use SetField to set the Quoted mark.
+func (t *Tuple) Set(key, value string) {
+	f := Field{Key: key, Value: value}
+
+	for _, tf := range *t {
+		if tf.Key == key {
+			f.Quoted = tf.Quoted
+			break
+		}
+	}
+
+	t.SetField(f)
+}
+
+// SetField sets the field with the key of f to f, the same way as Set, but
+// with the Quoted mark of f.
+func (t *Tuple) SetField(f Field) {
+	for i := range *t {
+		if (*t)[i].Key != f.Key {
+			continue
+		}
+
+		(*t)[i] = f
+
+		rest := (*t)[i+1:]
+		rest.delete(f.Key)
+		*t = (*t)[:i+1+len(rest)]
+
+		return
+	}
+
+	*t = append(*t, f)
+}
+
+// Delete removes all fields with the key.
+func (t *Tuple) Delete(key string) {
+	t.delete(key)
+}
+
+func (t *Tuple) delete(key string) {
+	n := 0
+	for _, f := range *t {
+		if f.Key != key {
+			(*t)[n] = f
+			n++
+		}
+	}
+
+	*t = (*t)[:n]
+}
+
+// Keys returns the keys of the tuple in the order of their first appearance.
+func (t Tuple) Keys() []string {
+	keys := make([]string, 0, len(t))
+	seen := make(map[string]bool, len(t))
+
+	for _, f := range t {
+		if !seen[f.Key] {
+			seen[f.Key] = true
+			keys = append(keys, f.Key)
+		}
+	}
+
+	return keys
+}
+
+// String returns the tuples encoding of t, i.e. "h=700,size=(w=1)".
+func (t Tuple) String() string {
+	var e encoder
+
+	e.opts = newOptions(nil)
+	if err := e.tuple(reflect.ValueOf(t)); err != nil {
+		return ""
+	}
+
+	return e.b.String()
+}
+
+// tupleFields decodes the tuple fields into the Tuple v in their order. Repeated
+// keys handled according to the duplicate keys policy, all of them kept unless
+// the policy drops them.
+func (d *decoder) tupleFields(v reflect.Value, flds []node) error {
+	flds, err := dedupe(flds, d.opts.duplicateKeys, d.s.pos)
+	if err != nil {
+		return err
+	}
+
+	t := make(Tuple, 0, len(flds))
+	for _, fld := range flds {
+		if !isEmpty(fld) {
+			t = append(t, nodeField(fld, d.s.opts))
+		}
+	}
+
+	v.Set(reflect.ValueOf(t))
+
+	return nil
+}
+
+// tuple writes the Tuple v fields in the stored order. Nested tuples and lists
+// are written as is, other values are quoted when needed.
+func (e *encoder) tuple(v reflect.Value) error {
+	for i := 0; i < v.Len(); i++ {
+		f := v.Index(i).Interface().(Field)
+		bare := e.opts.bareKeys && f.Value == ""
+
+		if err := e.writeKey(f.Key, i, bare); err != nil {
+			return &MarshalError{err}
+		}
+
+		if bare {
+			continue
+		}
+
+		if _, err := e.b.WriteString(e.opts.tupleValue(f)); err != nil {
+			return &MarshalError{err}
+		}
+	}
+
+	return nil
+}
+
+// tupleValue returns the value of the field f as it's written. Nested tuples
+// and lists are written as is, other values are quoted when needed.
+func (o *options) tupleValue(f Field) string {
+	if !f.Quoted && isStructured(f.Value, o.scannerOpts()) {
+		return f.Value
+	}
+
+	return o.quoteValue(f.Value)
+}
+
+// isStructured reports whether s is a well-formed nested tuple or list, i.e.
+// "(h=700,w=350)", that can be written without quotes.
+func isStructured(s string, opts scannerOptions) bool {
+	if s == "" || (s[0] != tupleOpen && s[0] != listOpen) {
+		return false
+	}
+
+	// The value must be read back as one tuple.
+	if adv, token, _ := opts.scanTuples([]byte(s), true); adv != len(s) || string(token) != s {
+		return false
+	}
+
+	p := parser{s: s, opts: opts}
+	_, err := p.value(0)
+
+	return err == nil && p.eof()
+}
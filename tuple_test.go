@@ -0,0 +1,230 @@
+package tuples_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/antklim/tuples"
+)
+
+type TTupleField struct {
+	Name string       `tuples:"name"`
+	Meta tuples.Tuple `tuples:"meta"`
+}
+
+func TestTupleMethods(t *testing.T) {
+	tuple := tuples.Tuple{{Key: "h", Value: "700"}, {Key: "w", Value: "350"}, {Key: "h", Value: "900"}}
+
+	if v := tuple.Get("h"); v != "900" {
+		t.Errorf("Get(h) = %q, want %q", v, "900")
+	}
+
+	if v, ok := tuple.Lookup("f"); ok || v != "" {
+		t.Errorf("Lookup(f) = %q, %t, want \"\", false", v, ok)
+	}
+
+	if keys := tuple.Keys(); !reflect.DeepEqual(keys, []string{"h", "w"}) {
+		t.Errorf("Keys() = %v, want [h w]", keys)
+	}
+
+	tuple.Set("h", "1")
+	tuple.Set("f", "png")
+
+	expected := tuples.Tuple{{Key: "h", Value: "1"}, {Key: "w", Value: "350"}, {Key: "f", Value: "png"}}
+	if !reflect.DeepEqual(tuple, expected) {
+		t.Errorf("Set() output:\ngot  %v\nwant %v", tuple, expected)
+	}
+
+	tuple.Delete("w")
+	tuple.Delete("x")
+
+	expected = tuples.Tuple{{Key: "h", Value: "1"}, {Key: "f", Value: "png"}}
+	if !reflect.DeepEqual(tuple, expected) {
+		t.Errorf("Delete() output:\ngot  %v\nwant %v", tuple, expected)
+	}
+
+	var empty tuples.Tuple
+	empty.Set("a", "b")
+
+	if s := empty.String(); s != "a=b" {
+		t.Errorf("String() = %q, want %q", s, "a=b")
+	}
+}
+
+func TestTupleString(t *testing.T) {
+	testCases := []struct {
+		in  tuples.Tuple
+		out string
+	}{
+		{
+			in:  tuples.Tuple{{Key: "w", Value: "350"}, {Key: "h", Value: "700"}},
+			out: "w=350,h=700",
+		},
+		{
+			in:  tuples.Tuple{{Key: "size", Value: "(h=1,n=\"a b\")"}, {Key: "l", Value: "[1,2]"}},
+			out: `size=(h=1,n="a b"),l=[1,2]`,
+		},
+		{
+			in:  tuples.Tuple{{Key: "note", Value: "(a b)"}, {Key: "x", Value: "(h)"}, {Key: "e"}},
+			out: `note="(a b)",x="(h)",e=""`,
+		},
+	}
+
+	for tI, tC := range testCases {
+		if out := tC.in.String(); out != tC.out {
+			t.Errorf("#%d: String() output:\ngot  %s\nwant %s", tI, out, tC.out)
+		}
+	}
+}
+
+func TestTupleDecodeEncode(t *testing.T) {
+	in := `w=350,h=700,tag=a,size=(h=1,w=2),tag=b,note="a b" f=png`
+
+	var out []tuples.Tuple
+	if err := tuples.Unmarshal([]byte(in), &out); err != nil {
+		t.Fatalf("unexpected Unmarshal() error: %v", err)
+	}
+
+	expected := []tuples.Tuple{
+		{
+			{Key: "w", Value: "350"},
+			{Key: "h", Value: "700"},
+			{Key: "tag", Value: "a"},
+			{Key: "size", Value: "(h=1,w=2)"},
+			{Key: "tag", Value: "b"},
+			{Key: "note", Value: "a b"},
+		},
+		{{Key: "f", Value: "png"}},
+	}
+
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("Unmarshal() output:\ngot  %v\nwant %v", out, expected)
+	}
+
+	b, err := tuples.Marshal(out)
+	if err != nil {
+		t.Fatalf("unexpected Marshal() error: %v", err)
+	}
+
+	if string(b) != in {
+		t.Errorf("Marshal() output:\ngot  %s\nwant %s", b, in)
+	}
+}
+
+func TestTupleQuotedStructuredValues(t *testing.T) {
+	in := `n="(a=1)",m="[x]",s=(a=1),l=[x]`
+
+	doc, err := tuples.ParseDocument([]byte(in))
+	if err != nil {
+		t.Fatalf("unexpected ParseDocument() error: %v", err)
+	}
+
+	if out := doc.String(); out != in {
+		t.Errorf("String() output:\ngot  %s\nwant %s", out, in)
+	}
+
+	tuple := doc.Tuples()[0]
+	if v := tuple.Get("n"); v != "(a=1)" {
+		t.Errorf("Get() = %s, want (a=1)", v)
+	}
+
+	expected := tuples.Tuple{
+		{Key: "n", Value: "(a=1)", Quoted: true},
+		{Key: "m", Value: "[x]", Quoted: true},
+		{Key: "s", Value: "(a=1)"},
+		{Key: "l", Value: "[x]"},
+	}
+	if !reflect.DeepEqual(tuple, expected) {
+		t.Errorf("Tuples() output:\ngot  %v\nwant %v", tuple, expected)
+	}
+
+	// A value got with Get and set back is written the same way.
+	for _, key := range tuple.Keys() {
+		tuple.Set(key, tuple.Get(key))
+	}
+
+	if out := tuple.String(); out != in {
+		t.Errorf("String() output:\ngot  %s\nwant %s", out, in)
+	}
+
+	tuple.Set("n", "(b=2)")
+	tuple.SetField(tuples.Field{Key: "s", Value: "(b=2)", Quoted: true})
+	tuple.SetField(tuples.Field{Key: "x", Value: "[y]"})
+
+	if out, want := tuple.String(), `n="(b=2)",m="[x]",s="(b=2)",l=[x],x=[y]`; out != want {
+		t.Errorf("String() output:\ngot  %s\nwant %s", out, want)
+	}
+}
+
+func TestTupleQuotedStructuredUpdate(t *testing.T) {
+	in := `id=1,note="(a=1)"`
+
+	doc, err := tuples.ParseDocument([]byte(in))
+	if err != nil {
+		t.Fatalf("unexpected ParseDocument() error: %v", err)
+	}
+
+	doc.Update(tuples.Where("id", "1"), "note", "(a=1)")
+
+	if out := doc.String(); out != in {
+		t.Errorf("String() output:\ngot  %s\nwant %s", out, in)
+	}
+
+	var out []struct {
+		ID   int    `tuples:"id"`
+		Note string `tuples:"note"`
+	}
+
+	if err := doc.Decode(&out); err != nil {
+		t.Fatalf("unexpected Decode() error: %v", err)
+	}
+
+	if len(out) != 1 || out[0].Note != "(a=1)" {
+		t.Errorf("Decode() output: %+v, want note (a=1)", out)
+	}
+
+	overlay, err := tuples.ParseDocument([]byte(`note="(b=2)",+tag="[x]"`))
+	if err != nil {
+		t.Fatalf("unexpected ParseDocument() error: %v", err)
+	}
+
+	if out, want := tuples.Merge(doc, overlay).String(), `id=1,note="(b=2)",tag="[x]"`; out != want {
+		t.Errorf("Merge() output:\ngot  %s\nwant %s", out, want)
+	}
+}
+
+func TestTupleField(t *testing.T) {
+	in := "name=a,meta=(z=1,a=2)"
+
+	var out []TTupleField
+	if err := tuples.Unmarshal([]byte(in), &out); err != nil {
+		t.Fatalf("unexpected Unmarshal() error: %v", err)
+	}
+
+	expected := []TTupleField{{Name: "a", Meta: tuples.Tuple{{Key: "z", Value: "1"}, {Key: "a", Value: "2"}}}}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("Unmarshal() output:\ngot  %v\nwant %v", out, expected)
+	}
+
+	b, err := tuples.Marshal(out)
+	if err != nil {
+		t.Fatalf("unexpected Marshal() error: %v", err)
+	}
+
+	if string(b) != in {
+		t.Errorf("Marshal() output:\ngot  %s\nwant %s", b, in)
+	}
+}
+
+func TestTupleDuplicateKeys(t *testing.T) {
+	var out []tuples.Tuple
+
+	err := tuples.Unmarshal([]byte("a=1,a=2"), &out, tuples.WithDuplicateKeys(tuples.DuplicateKeysFirstWins))
+	if err != nil {
+		t.Fatalf("unexpected Unmarshal() error: %v", err)
+	}
+
+	if expected := []tuples.Tuple{{{Key: "a", Value: "1"}}}; !reflect.DeepEqual(out, expected) {
+		t.Errorf("Unmarshal() output:\ngot  %v\nwant %v", out, expected)
+	}
+}
@@ -0,0 +1,246 @@
+package tuples
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var (
+	errEmptyTuple = errors.New("empty tuple")
+	errInvalidKey = errors.New("invalid key")
+	errHeaderKey  = errors.New("key not in header")
+)
+
+// WriterError describes an error that occurred while writing a tuple.
+type WriterError struct {
+	err error
+}
+
+func (e *WriterError) Error() string {
+	return fmt.Sprintf("tuples: write failed: %s", e.err)
+}
+
+func (e *WriterError) Unwrap() error {
+	return e.err
+}
+
+// Writer describes a tuples writer. It is the counterpart of the Reader.
+// Tuples are buffered, call Flush to make sure they are written to the
+// underlying io.Writer.
+type Writer struct {
+	w      *bufio.Writer
+	opts   options
+	n      int      // number of written tuples
+	header []string // keys of the written header tuple
+}
+
+// NewWriter creates a new instance of the Writer. It accepts the same options
+// as the Reader, i.e. delimiters.
+// If writer creation fails it returns error.
+func NewWriter(w io.Writer, opts ...Option) (*Writer, error) {
+	wopts := newOptions(opts)
+	if err := wopts.validate(); err != nil {
+		return nil, err
+	}
+
+	return &Writer{w: bufio.NewWriter(w), opts: wopts}, nil
+}
+
+// Write writes one tuple with fields in the given order. Nested tuples and
+// lists, i.e. "(h=1,w=2)", are written as is. Other values that contain
+// whitespace, delimiters, brackets or quotes are quoted, i.e. `note="a b"`.
+// Keys are never quoted, so a key with such characters causes WriterError.
+// Empty values are written as bare keys with the WithBareKeys option, and
+// only values are written with the WithPositional option. With the WithHeader
+// option the keys of the first tuple are written as the header tuple, and the
+// values of every tuple are written in the header keys order. Missing keys
+// are written as empty fields and keys not in the header cause WriterError.
+func (w *Writer) Write(tuple []Field) error {
+	if len(tuple) == 0 {
+		return &WriterError{errEmptyTuple}
+	}
+
+	if !w.opts.positional {
+		for _, f := range tuple {
+			if !w.opts.validKey(f.Key) {
+				return &WriterError{fmt.Errorf("%w %q", errInvalidKey, f.Key)}
+			}
+		}
+	}
+
+	if w.opts.header {
+		return w.writeHeaderValues(tuple)
+	}
+
+	if err := w.writeDelimiter(); err != nil {
+		return err
+	}
+
+	for i, f := range tuple {
+		if i > 0 {
+			if _, err := w.w.WriteRune(w.opts.fieldsDelimiter); err != nil {
+				return err
+			}
+		}
+
+		if err := w.writeField(f); err != nil {
+			return err
+		}
+	}
+
+	w.n++
+
+	return nil
+}
+
+func (w *Writer) writeDelimiter() error {
+	if w.n == 0 {
+		return nil
+	}
+
+	_, err := w.w.WriteRune(tuplesDelimiter)
+
+	return err
+}
+
+// writeHeaderValues writes the header tuple before the first tuple and the
+// values of the tuple in the header keys order.
+func (w *Writer) writeHeaderValues(tuple []Field) error {
+	if w.header == nil {
+		w.header = Tuple(tuple).Keys()
+	}
+
+	if w.n == 0 {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	return w.writeValues(tuple, w.header)
+}
+
+// writeHeader writes the header keys as the header tuple, i.e. "@h,w,f".
+func (w *Writer) writeHeader() error {
+	if err := w.writeDelimiter(); err != nil {
+		return err
+	}
+
+	if _, err := w.w.WriteString(string(headerMark) + strings.Join(w.header, string(w.opts.fieldsDelimiter))); err != nil {
+		return err
+	}
+
+	w.n++
+
+	return nil
+}
+
+// writeValues writes the values of the tuple in the keys order. Missing keys
+// are written as empty fields, other keys cause WriterError.
+func (w *Writer) writeValues(tuple []Field, keys []string) error {
+	vals := make(map[string]Field, len(tuple))
+	for _, f := range tuple {
+		if !slices.Contains(keys, f.Key) {
+			return &WriterError{fmt.Errorf("%w %q", errHeaderKey, f.Key)}
+		}
+
+		vals[f.Key] = f
+	}
+
+	if err := w.writeDelimiter(); err != nil {
+		return err
+	}
+
+	for i, key := range keys {
+		if i > 0 {
+			if _, err := w.w.WriteRune(w.opts.fieldsDelimiter); err != nil {
+				return err
+			}
+		}
+
+		if f, ok := vals[key]; ok {
+			if _, err := w.w.WriteString(w.opts.tupleValue(f)); err != nil {
+				return err
+			}
+		}
+	}
+
+	w.n++
+
+	return nil
+}
+
+func (w *Writer) writeField(f Field) error {
+	if !w.opts.positional {
+		if _, err := w.w.WriteString(f.Key); err != nil {
+			return err
+		}
+
+		if w.opts.bareKeys && f.Value == "" {
+			return nil
+		}
+
+		if _, err := w.w.WriteRune(w.opts.keyValDelimiter); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.w.WriteString(w.opts.tupleValue(f))
+
+	return err
+}
+
+// validKey reports whether the key can be read back, i.e. it is not empty and
+// does not contain whitespace, delimiters, brackets or quotes.
+func (o *options) validKey(key string) bool {
+	return key != "" && strings.IndexFunc(key, func(r rune) bool {
+		return unicode.IsSpace(r) || r == o.fieldsDelimiter || r == o.keyValDelimiter ||
+			isBracket(r) || r == valueQuote
+	}) < 0
+}
+
+// WriteMap writes one tuple with fields of the map m in the sorted keys
+// order.
+func (w *Writer) WriteMap(m map[string]string) error {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	tuple := make([]Field, 0, len(keys))
+	for _, key := range keys {
+		tuple = append(tuple, Field{Key: key, Value: m[key]})
+	}
+
+	return w.Write(tuple)
+}
+
+// WriteAll writes multiple tuples using Write and then calls Flush.
+func (w *Writer) WriteAll(tuples [][]Field) error {
+	for _, tuple := range tuples {
+		if err := w.Write(tuple); err != nil {
+			return err
+		}
+	}
+
+	return w.w.Flush()
+}
+
+// Flush writes any buffered data to the underlying io.Writer. To check if an
+// error occurred during the Flush, call Error.
+func (w *Writer) Flush() {
+	_ = w.w.Flush()
+}
+
+// Error reports any error that has occurred during a previous Write or Flush.
+func (w *Writer) Error() error {
+	_, err := w.w.Write(nil)
+	return err
+}
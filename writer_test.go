@@ -0,0 +1,190 @@
+package tuples_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/antklim/tuples"
+)
+
+type writeTest struct {
+	desc string
+	in   [][]tuples.Field
+	out  string
+	err  error
+	opts []tuples.Option
+}
+
+var writeTests = []writeTest{
+	{
+		desc: "Simple",
+		in: [][]tuples.Field{
+			{{Key: "h", Value: "700"}, {Key: "w", Value: "350"}},
+			{{Key: "f", Value: "png"}},
+		},
+		out: "h=700,w=350 f=png",
+	},
+	{
+		desc: "Quoted values",
+		in: [][]tuples.Field{
+			{{Key: "note", Value: "a b, c=d"}, {Key: "q", Value: `"hi"`}, {Key: "e"}, {Key: "in", Value: `5"`}},
+			{{Key: "size", Value: "(h=1)"}, {Key: "l", Value: "[a,b]"}, {Key: "bad", Value: "(h=1"}},
+		},
+		out: `note="a b, c=d",q="""hi""",e="",in=5" size=(h=1),l=[a,b],bad="(h=1"`,
+	},
+	{
+		desc: "BareKeys",
+		in:   [][]tuples.Field{{{Key: "ro"}, {Key: "uid", Value: "0"}}},
+		out:  "ro,uid=0",
+		opts: []tuples.Option{tuples.WithBareKeys()},
+	},
+	{
+		desc: "Positional",
+		in:   [][]tuples.Field{{{Value: "700"}, {}, {Value: "a b"}}},
+		out:  `700,"","a b"`,
+		opts: []tuples.Option{tuples.WithPositional()},
+	},
+	{
+		desc: "Header",
+		in: [][]tuples.Field{
+			{{Key: "h", Value: "700"}, {Key: "w", Value: "350"}, {Key: "f", Value: "a b"}},
+			{{Key: "f", Value: "png"}, {Key: "h", Value: "900"}},
+			{{Key: "w", Value: ""}},
+		},
+		out:  `@h,w,f 700,350,"a b" 900,,png ,"",`,
+		opts: []tuples.Option{tuples.WithHeader()},
+	},
+	{
+		desc: "HeaderUnknownKey",
+		in:   [][]tuples.Field{{{Key: "h", Value: "700"}}, {{Key: "h", Value: "900"}, {Key: "w", Value: "450"}}},
+		out:  "@h 700",
+		err:  errors.New(`tuples: write failed: key not in header "w"`),
+		opts: []tuples.Option{tuples.WithHeader()},
+	},
+	{
+		desc: "CustomDelimiters",
+		in:   [][]tuples.Field{{{Key: "h", Value: "7;0"}, {Key: "w", Value: "a=b"}}},
+		out:  `h:"7;0";w:a=b`,
+		opts: []tuples.Option{tuples.WithFieldsDelimiter(';'), tuples.WithKeyValueDelimiter(':')},
+	},
+	{
+		desc: "EmptyTuple",
+		in:   [][]tuples.Field{{{Key: "h", Value: "1"}}, {}},
+		out:  "h=1",
+		err:  errors.New("tuples: write failed: empty tuple"),
+	},
+	{
+		desc: "InvalidKey",
+		in:   [][]tuples.Field{{{Key: "h", Value: "1"}}, {{Key: "a b", Value: "1"}}},
+		out:  "h=1",
+		err:  errors.New(`tuples: write failed: invalid key "a b"`),
+	},
+	{
+		desc: "EmptyKey",
+		in:   [][]tuples.Field{{{Value: "1"}}},
+		err:  errors.New(`tuples: write failed: invalid key ""`),
+	},
+}
+
+func TestWrite(t *testing.T) {
+	for tI, tC := range writeTests {
+		t.Run(tC.desc, func(t *testing.T) {
+			var b bytes.Buffer
+
+			w, err := tuples.NewWriter(&b, tC.opts...)
+			if err != nil {
+				t.Fatalf("#%d: unexpected NewWriter() error: %v", tI, err)
+			}
+
+			for _, tuple := range tC.in {
+				if err = w.Write(tuple); err != nil {
+					break
+				}
+			}
+
+			if !eqErrors(err, tC.err) {
+				t.Errorf("#%d: Write() error mismatch:\ngot  %v\nwant %v", tI, err, tC.err)
+			}
+
+			w.Flush()
+
+			if err := w.Error(); err != nil {
+				t.Errorf("#%d: unexpected Error(): %v", tI, err)
+			}
+
+			if out := b.String(); out != tC.out {
+				t.Errorf("#%d: Write() output:\ngot  %s\nwant %s", tI, out, tC.out)
+			}
+		})
+	}
+}
+
+func TestWriteReadBack(t *testing.T) {
+	in := [][]tuples.Field{
+		{{Key: "note", Value: `a "b", (c=d)`}, {Key: "e"}, {Key: "t", Value: "x\ty"}},
+		{{Key: "f", Value: "png"}},
+	}
+
+	var b bytes.Buffer
+
+	w, err := tuples.NewWriter(&b)
+	if err != nil {
+		t.Fatalf("unexpected NewWriter() error: %v", err)
+	}
+
+	if err := w.WriteAll(in); err != nil {
+		t.Fatalf("unexpected WriteAll() error: %v", err)
+	}
+
+	r, err := tuples.NewReader(&b)
+	if err != nil {
+		t.Fatalf("unexpected NewReader() error: %v", err)
+	}
+
+	var out [][]tuples.Field
+	for flds, err := range r.Fields() {
+		if err != nil {
+			t.Fatalf("unexpected Fields() error: %v", err)
+		}
+
+		out = append(out, flds)
+	}
+
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("read back output:\ngot  %v\nwant %v", out, in)
+	}
+}
+
+func TestWriteMap(t *testing.T) {
+	var b bytes.Buffer
+
+	w, err := tuples.NewWriter(&b)
+	if err != nil {
+		t.Fatalf("unexpected NewWriter() error: %v", err)
+	}
+
+	if err := w.WriteMap(map[string]string{"w": "350", "h": "700"}); err != nil {
+		t.Fatalf("unexpected WriteMap() error: %v", err)
+	}
+
+	if err := w.WriteMap(map[string]string{"f": "a b"}); err != nil {
+		t.Fatalf("unexpected WriteMap() error: %v", err)
+	}
+
+	w.Flush()
+
+	if out, expected := b.String(), `h=700,w=350 f="a b"`; out != expected {
+		t.Errorf("WriteMap() output:\ngot  %s\nwant %s", out, expected)
+	}
+}
+
+func TestNewWriterFails(t *testing.T) {
+	_, err := tuples.NewWriter(&bytes.Buffer{}, tuples.WithFieldsDelimiter('='))
+
+	expected := errors.New("tuples: invalid delimiters: fields and key-value delimiters are equal")
+	if !eqErrors(err, expected) {
+		t.Errorf("NewWriter() error mismatch:\ngot  %v\nwant %v", err, expected)
+	}
+}